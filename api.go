@@ -0,0 +1,209 @@
+package code
+
+import (
+	"code/internal/formatters"
+	"code/internal/models"
+	"io"
+)
+
+// DiffNode is one node of a diff tree: a key (or array element) together
+// with how its value changed. Nested and array nodes hold their children.
+type DiffNode = models.DiffNode
+
+// NodeType tells what happened to the value of a DiffNode.
+type NodeType = models.NodeType
+
+// Node types of a diff tree.
+const (
+	NodeTypeAdded       = models.NodeTypeAdded
+	NodeTypeRemoved     = models.NodeTypeRemoved
+	NodeTypeChanged     = models.NodeTypeChanged
+	NodeTypeUnchanged   = models.NodeTypeUnchanged
+	NodeTypeNested      = models.NodeTypeNested
+	NodeTypeArray       = models.NodeTypeArray
+	NodeTypeEquivalent  = models.NodeTypeEquivalent
+	NodeTypeTypeChanged = models.NodeTypeTypeChanged
+)
+
+// ThreeWayNode is one node of a three-way diff tree: a key together with
+// its value in the base, ours and theirs files and how it changed.
+type ThreeWayNode = models.ThreeWayNode
+
+// ThreeWayStatus tells on which side the value of a ThreeWayNode changed.
+type ThreeWayStatus = models.ThreeWayStatus
+
+// Statuses of a three-way diff tree.
+const (
+	ThreeWayUnchanged = models.ThreeWayUnchanged
+	ThreeWayOurs      = models.ThreeWayOurs
+	ThreeWayTheirs    = models.ThreeWayTheirs
+	ThreeWayBoth      = models.ThreeWayBoth
+	ThreeWayConflict  = models.ThreeWayConflict
+	ThreeWayNested    = models.ThreeWayNested
+)
+
+// Matrix holds the values of every key path across several documents, see
+// DiffMatrix.
+type Matrix = models.Matrix
+
+// MatrixRow is one key path of a Matrix with its value in each document.
+type MatrixRow = models.MatrixRow
+
+// FileDiff is one file of a directory comparison, see DiffDirs.
+type FileDiff = models.FileDiff
+
+// FileStatus tells what happened to the file of a FileDiff.
+type FileStatus = models.FileStatus
+
+// Statuses of a FileDiff.
+const (
+	FileAdded     = models.FileAdded
+	FileRemoved   = models.FileRemoved
+	FileChanged   = models.FileChanged
+	FileUnchanged = models.FileUnchanged
+)
+
+// DateTime is how date and time values of formats such as TOML appear in
+// decoded documents and diff trees.
+type DateTime = models.DateTime
+
+// DateTimeKind tells apart the flavours of DateTime.
+type DateTimeKind = models.DateTimeKind
+
+// Kinds of DateTime.
+const (
+	DateTimeOffset = models.DateTimeOffset
+	DateTimeLocal  = models.DateTimeLocal
+	DateLocal      = models.DateLocal
+	TimeLocal      = models.TimeLocal
+)
+
+// Diff compares two decoded documents and returns their diff tree, sorted
+// by key at each level. Values are expected to be what encoding/json or
+// gopkg.in/yaml.v3 produce when decoding into map[string]any.
+// It fails only on invalid options, or with ErrTypeChanged under
+// WithFailOnTypeChange.
+func Diff(old, new map[string]any, opts ...Option) ([]DiffNode, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	nodes := buildDiffTree(old, new, nil, options)
+	return nodes, checkTypeChanges(nodes, options)
+}
+
+// HasChanges reports whether a diff tree holds any difference, that is any
+// node other than an unchanged or equivalent one at any depth.
+func HasChanges(nodes []DiffNode) bool {
+	for _, node := range nodes {
+		if nodeChanged(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasThreeWayChanges reports whether ours or theirs changed anything of
+// their common base.
+func HasThreeWayChanges(nodes []ThreeWayNode) bool {
+	for _, node := range nodes {
+		if node.Status != ThreeWayUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffReaders reads two documents, decodes them according to their declared
+// formats and returns their diff tree. A format is the name of a registered
+// input format (see InputFormats) or one of its extensions, such as "yml";
+// an empty format is guessed from the content.
+func DiffReaders(old io.Reader, oldFormat string, new io.Reader, newFormat string, opts ...Option) ([]DiffNode, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	filesData := make([]models.FileData, 0, 2)
+	for _, in := range []struct {
+		r      io.Reader
+		format string
+	}{{old, oldFormat}, {new, newFormat}} {
+		data, err := io.ReadAll(in.r)
+		if err != nil {
+			return nil, err
+		}
+
+		format := in.format
+		if format == "" {
+			format, err = sniffFormat("input", data, "")
+		} else {
+			format, err = normalizeInputFormat(format)
+		}
+		if err != nil {
+			return nil, err
+		}
+		filesData = append(filesData, models.FileData{Content: data, Format: format})
+	}
+
+	maps, err := decodeFiles(filesData, options)
+	if err != nil {
+		return nil, err
+	}
+	nodes := buildDiffTree(maps[0], maps[1], nil, options)
+	return nodes, checkTypeChanges(nodes, options)
+}
+
+// DiffThreeWay compares two decoded documents derived from a common base
+// and returns their three-way diff tree, sorted by key at each level.
+// It fails only on invalid options.
+func DiffThreeWay(base, ours, theirs map[string]any, opts ...Option) ([]ThreeWayNode, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return buildThreeWayDiff(base, ours, theirs, options), nil
+}
+
+// FormatThreeWay renders a three-way diff tree as "stylish", "plain" or
+// "json".
+func FormatThreeWay(nodes []ThreeWayNode, format string) (string, error) {
+	return formatters.FormatThreeWay(nodes, format)
+}
+
+// FormatMatrix renders a comparison matrix as "stylish", "markdown" or
+// "json".
+func FormatMatrix(matrix Matrix, format string) (string, error) {
+	return formatters.FormatMatrix(matrix, format)
+}
+
+// FormatDir renders a directory comparison as "stylish", "plain" or
+// "json".
+func FormatDir(files []FileDiff, format string) (string, error) {
+	return formatters.FormatDir(files, format)
+}
+
+// Format renders a diff tree with the named output format: one of the
+// built-in "stylish", "plain", "json", "jsonpatch" and "mergepatch", or one
+// added with RegisterFormatter.
+func Format(nodes []DiffNode, format string) (string, error) {
+	return formatters.Format(nodes, format)
+}
+
+// Formatter renders a diff tree as text.
+type Formatter = formatters.Formatter
+
+// FormatterFunc adapts an ordinary function to the Formatter interface.
+type FormatterFunc = formatters.FormatterFunc
+
+// RegisterFormatter makes f available as an output format under name, for
+// Format, GenDiff and the --format flag of gendiff. It fails when the name
+// is empty or already taken, so built-in formats cannot be replaced.
+func RegisterFormatter(name string, f Formatter) error {
+	return formatters.Register(name, f)
+}
+
+// Formats returns the names of all registered output formats, sorted.
+func Formats() []string {
+	return formatters.Names()
+}
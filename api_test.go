@@ -0,0 +1,180 @@
+package code_test
+
+import (
+	"code"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"host": "localhost", "ports": []any{80, 443}}
+	new := map[string]any{"host": "example.com", "ports": []any{80, 443}}
+
+	nodes, err := code.Diff(old, new)
+	r.NoError(err)
+	r.Equal([]code.DiffNode{
+		{Key: "host", Type: code.NodeTypeChanged, OldValue: "localhost", NewValue: "example.com"},
+		{
+			Key:      "ports",
+			Type:     code.NodeTypeArray,
+			OldValue: []any{80, 443},
+			NewValue: []any{80, 443},
+			Children: []code.DiffNode{
+				{Key: "0", Type: code.NodeTypeUnchanged, OldValue: 80},
+				{Key: "1", Type: code.NodeTypeUnchanged, OldValue: 443},
+			},
+		},
+	}, nodes)
+
+	_, err = code.Diff(old, new, code.WithArrayDiff("unknown"))
+	r.Error(err)
+}
+
+func TestHasChanges(t *testing.T) {
+	r := require.New(t)
+
+	same := map[string]any{"db": map[string]any{"ports": []any{5432}}}
+	nodes, err := code.Diff(same, same)
+	r.NoError(err)
+	r.False(code.HasChanges(nodes))
+
+	nodes, err = code.Diff(same, map[string]any{"db": map[string]any{"ports": []any{5432, 5433}}})
+	r.NoError(err)
+	r.True(code.HasChanges(nodes))
+
+	tree, err := code.DiffThreeWay(same, same, same)
+	r.NoError(err)
+	r.False(code.HasThreeWayChanges(tree))
+
+	tree, err = code.DiffThreeWay(same, same, map[string]any{})
+	r.NoError(err)
+	r.True(code.HasThreeWayChanges(tree))
+}
+
+func TestDiffReaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		old, new  string
+		oldFormat string
+		newFormat string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "declared formats",
+			old:       "host: localhost\n",
+			oldFormat: "yaml",
+			new:       `{"host": "example.com"}`,
+			newFormat: "json",
+			want:      "Property 'host' was updated. From 'localhost' to 'example.com'",
+		},
+		{
+			name:      "guessed format",
+			old:       "host = \"localhost\"\n",
+			new:       `{"host": "localhost", "port": 80}`,
+			newFormat: "json",
+			want:      "Property 'port' was added with value: 80",
+		},
+		{
+			name:      "unknown format",
+			old:       `{}`,
+			oldFormat: "xml",
+			new:       `{}`,
+			newFormat: "json",
+			wantErr:   true,
+		},
+		{
+			name:      "content does not match the format",
+			old:       `{}`,
+			oldFormat: "json",
+			new:       `{`,
+			newFormat: "json",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			nodes, err := code.DiffReaders(strings.NewReader(tt.old), tt.oldFormat, strings.NewReader(tt.new), tt.newFormat)
+
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+			got, err := code.Format(nodes, "plain")
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestFormatUnknown(t *testing.T) {
+	_, err := code.Format(nil, "xml")
+	require.Error(t, err)
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	r := require.New(t)
+
+	count := code.FormatterFunc(func(nodes []code.DiffNode) (string, error) {
+		changed := 0
+		for _, n := range nodes {
+			if n.Type != code.NodeTypeUnchanged {
+				changed++
+			}
+		}
+		return strings.Repeat("*", changed), nil
+	})
+
+	r.NoError(code.RegisterFormatter("test-count", count))
+	r.Contains(code.Formats(), "test-count")
+	r.Error(code.RegisterFormatter("test-count", count))
+	r.Error(code.RegisterFormatter("stylish", count))
+	r.Error(code.RegisterFormatter("", count))
+	r.Error(code.RegisterFormatter("test-nil", nil))
+
+	got, err := code.GenDiff("testdata/fixture/file1.json", "testdata/fixture/file2.json", "test-count")
+	r.NoError(err)
+	r.Equal("****", got)
+
+	_, err = code.Format(nil, "xml")
+	r.ErrorContains(err, "test-count")
+}
+
+func TestRegisterParser(t *testing.T) {
+	r := require.New(t)
+
+	// "key -> value" lines
+	arrows := code.ParserFunc(func(data []byte) (map[string]any, error) {
+		m := make(map[string]any)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			key, value, _ := strings.Cut(line, "->")
+			m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		return m, nil
+	})
+
+	r.NoError(code.RegisterParser("application/x-arrows", arrows, ".arrows"))
+	r.Contains(code.InputFormats(), "application/x-arrows")
+	r.Error(code.RegisterParser("application/x-arrows", arrows))
+	r.Error(code.RegisterParser("arrows2", arrows, ".json"))
+	r.Error(code.RegisterParser("arrows3", arrows, "arrows3"))
+	r.Error(code.RegisterParser("", arrows))
+
+	nodes, err := code.DiffReaders(
+		strings.NewReader("host -> localhost"), "application/x-arrows",
+		strings.NewReader("host -> example.com"), "arrows",
+	)
+	r.NoError(err)
+	got, err := code.Format(nodes, "plain")
+	r.NoError(err)
+	r.Equal("Property 'host' was updated. From 'localhost' to 'example.com'", got)
+}
@@ -0,0 +1,244 @@
+package code
+
+import (
+	"code/internal/models"
+	"fmt"
+	"strconv"
+)
+
+// buildArrayDiff compares two lists found at path and returns one DiffNode
+// per element. When an identity field is configured for the path and every
+// element carries a distinct value for it, elements are matched by that
+// value and keyed as "field=value". Otherwise elements are paired according
+// to opts.ArrayDiff and keyed by decimal index: the new index for elements
+// present in the new list, the old index for removed ones. Elements left
+// out by Ignore or Only stay as placeholders, see keepNodes.
+func buildArrayDiff(old, new []any, path []string, opts Options) []models.DiffNode {
+	var nodes []models.DiffNode
+	if field, ok := opts.arrayKeyField(path); ok {
+		nodes, ok = buildKeyedArrayDiff(old, new, field, path, opts)
+		if ok {
+			return keepNodes(nodes, path, opts)
+		}
+	}
+	if opts.ArrayDiff == ArrayDiffLCS {
+		nodes = buildLCSArrayDiff(old, new, path, opts)
+	} else {
+		nodes = buildIndexArrayDiff(old, new, path, opts)
+	}
+	return keepNodes(nodes, path, opts)
+}
+
+// keepNodes filters the element nodes of an array. Unlike object members,
+// elements are addressed by position, so an element left out of the
+// comparison is not dropped but kept as an unchanged placeholder holding its
+// whole old value; the elements after it then keep their positions, e.g. in
+// JSON Patch operations. Left-out elements that were added are dropped, as
+// they have no position in the old list.
+func keepNodes(nodes []models.DiffNode, path []string, opts Options) []models.DiffNode {
+	if len(opts.ignore) == 0 && len(opts.only) == 0 {
+		return nodes
+	}
+	kept := nodes[:0]
+	for _, node := range nodes {
+		if filtered, ok := opts.filterNode(appendPath(path, node.Key), node); ok {
+			kept = append(kept, filtered)
+			continue
+		}
+		if node.Type != models.NodeTypeAdded {
+			kept = append(kept, models.DiffNode{Key: node.Key, Type: models.NodeTypeUnchanged, OldValue: node.OldValue})
+		}
+	}
+	return kept
+}
+
+// buildKeyedArrayDiff matches elements by the value of field regardless of
+// their order. Matched elements come first in new-list order, followed by
+// removed elements in old-list order. It reports false when an element is
+// not an object, lacks the field, or shares its identity with another one.
+func buildKeyedArrayDiff(old, new []any, field string, path []string, opts Options) ([]models.DiffNode, bool) {
+	oldKeys, ok := elementKeys(old, field)
+	if !ok {
+		return nil, false
+	}
+	newKeys, ok := elementKeys(new, field)
+	if !ok {
+		return nil, false
+	}
+
+	oldIndex := make(map[string]int, len(oldKeys))
+	for i, key := range oldKeys {
+		oldIndex[key] = i
+	}
+	inNew := make(map[string]struct{}, len(newKeys))
+
+	nodes := make([]models.DiffNode, 0, max(len(old), len(new)))
+	for j, key := range newKeys {
+		inNew[key] = struct{}{}
+		if i, ok := oldIndex[key]; ok {
+			nodes = append(nodes, diffValues(key, old[i], new[j], appendPath(path, key), opts))
+		} else {
+			nodes = append(nodes, models.DiffNode{Key: key, Type: models.NodeTypeAdded, NewValue: new[j]})
+		}
+	}
+	for i, key := range oldKeys {
+		if _, ok := inNew[key]; !ok {
+			nodes = append(nodes, models.DiffNode{Key: key, Type: models.NodeTypeRemoved, OldValue: old[i]})
+		}
+	}
+	return nodes, true
+}
+
+// elementKeys returns the "field=value" identity of every element.
+func elementKeys(list []any, field string) ([]string, bool) {
+	keys := make([]string, len(list))
+	seen := make(map[string]struct{}, len(list))
+	for i, elem := range list {
+		m, ok := elem.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		id, ok := m[field]
+		if !ok || isContainer(id) {
+			return nil, false
+		}
+		key := fmt.Sprintf("%s=%v", field, id)
+		if _, dup := seen[key]; dup {
+			return nil, false
+		}
+		seen[key] = struct{}{}
+		keys[i] = key
+	}
+	return keys, true
+}
+
+func isContainer(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// buildIndexArrayDiff compares two lists position by position:
+//   - Indices present only in the new list are added
+//   - Indices present only in the old list are removed
+//   - Indices present in both are compared like map values, so nested
+//     objects and lists produce nested and array nodes
+func buildIndexArrayDiff(old, new []any, path []string, opts Options) []models.DiffNode {
+	size := max(len(old), len(new))
+	nodes := make([]models.DiffNode, 0, size)
+	for i := 0; i < size; i++ {
+		switch {
+		case i >= len(new):
+			nodes = append(nodes, removedElement(i, old[i]))
+		case i >= len(old):
+			nodes = append(nodes, addedElement(i, new[i]))
+		default:
+			key := strconv.Itoa(i)
+			nodes = append(nodes, diffValues(key, old[i], new[i], appendPath(path, key), opts))
+		}
+	}
+	return nodes
+}
+
+// buildLCSArrayDiff aligns both lists on their longest common subsequence
+// of equal elements. Elements outside the subsequence form hunks between
+// two matches; inside a hunk removed and added elements are paired in order
+// and compared like index mode, the surplus is reported as removed or added.
+// Nodes are emitted in list order, hunk by hunk.
+func buildLCSArrayDiff(old, new []any, path []string, opts Options) []models.DiffNode {
+	nodes := make([]models.DiffNode, 0, max(len(old), len(new)))
+
+	i, j := 0, 0
+	// Only strictly equal elements are matched, so that equivalent ones end
+	// up in hunks and are reported as such
+	for _, match := range longestCommonSubsequence(old, new, opts.strict()) {
+		nodes = appendHunk(nodes, old, new, i, match[0], j, match[1], path, opts)
+		nodes = append(nodes, models.DiffNode{
+			Key:      strconv.Itoa(match[1]),
+			Type:     models.NodeTypeUnchanged,
+			OldValue: old[match[0]],
+		})
+		i, j = match[0]+1, match[1]+1
+	}
+	return appendHunk(nodes, old, new, i, len(old), j, len(new), path, opts)
+}
+
+func appendHunk(nodes []models.DiffNode, old, new []any, oldFrom, oldTo, newFrom, newTo int, path []string, opts Options) []models.DiffNode {
+	for oldFrom < oldTo && newFrom < newTo {
+		key := strconv.Itoa(newFrom)
+		nodes = append(nodes, diffValues(key, old[oldFrom], new[newFrom], appendPath(path, key), opts))
+		oldFrom++
+		newFrom++
+	}
+	for ; oldFrom < oldTo; oldFrom++ {
+		nodes = append(nodes, removedElement(oldFrom, old[oldFrom]))
+	}
+	for ; newFrom < newTo; newFrom++ {
+		nodes = append(nodes, addedElement(newFrom, new[newFrom]))
+	}
+	return nodes
+}
+
+// longestCommonSubsequence returns the index pairs {oldIndex, newIndex}
+// of one longest common subsequence of equal elements, in increasing order.
+// Common prefix and suffix are matched directly so the quadratic table only
+// covers the part of the lists that actually differs.
+func longestCommonSubsequence(old, new []any, opts Options) [][2]int {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && valuesEqual(old[prefix], new[prefix], opts) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix &&
+		valuesEqual(old[len(old)-1-suffix], new[len(new)-1-suffix], opts) {
+		suffix++
+	}
+
+	a, b := old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+
+	// lengths[x][y] is the LCS length of a[x:] and b[y:]
+	lengths := make([][]int, len(a)+1)
+	for x := range lengths {
+		lengths[x] = make([]int, len(b)+1)
+	}
+	for x := len(a) - 1; x >= 0; x-- {
+		for y := len(b) - 1; y >= 0; y-- {
+			if valuesEqual(a[x], b[y], opts) {
+				lengths[x][y] = lengths[x+1][y+1] + 1
+			} else {
+				lengths[x][y] = max(lengths[x+1][y], lengths[x][y+1])
+			}
+		}
+	}
+
+	matches := make([][2]int, 0, prefix+suffix+lengths[0][0])
+	for k := 0; k < prefix; k++ {
+		matches = append(matches, [2]int{k, k})
+	}
+	for x, y := 0, 0; x < len(a) && y < len(b); {
+		switch {
+		case valuesEqual(a[x], b[y], opts):
+			matches = append(matches, [2]int{prefix + x, prefix + y})
+			x++
+			y++
+		case lengths[x+1][y] >= lengths[x][y+1]:
+			x++
+		default:
+			y++
+		}
+	}
+	for k := suffix; k > 0; k-- {
+		matches = append(matches, [2]int{len(old) - k, len(new) - k})
+	}
+	return matches
+}
+
+func removedElement(index int, value any) models.DiffNode {
+	return models.DiffNode{Key: strconv.Itoa(index), Type: models.NodeTypeRemoved, OldValue: value}
+}
+
+func addedElement(index int, value any) models.DiffNode {
+	return models.DiffNode{Key: strconv.Itoa(index), Type: models.NodeTypeAdded, NewValue: value}
+}
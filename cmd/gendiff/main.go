@@ -1,10 +1,13 @@
 package main
 
 import (
+	"code"
 	"code/internal/parsers"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/urfave/cli/v3"
 )
@@ -13,32 +16,318 @@ var flags = []cli.Flag{
 	&cli.StringFlag{
 		Name:    "format",
 		Aliases: []string{"f"},
-		Usage:   "output format (stylish, plain, json)",
+		Usage:   "output format (" + strings.Join(code.Formats(), ", ") + ")",
 		Value:   "stylish",
 	},
+	&cli.StringFlag{
+		Name:  "array-diff",
+		Usage: "how array elements are paired (index, lcs)",
+		Value: string(code.ArrayDiffIndex),
+	},
+	&cli.StringSliceFlag{
+		Name:  "array-key",
+		Usage: "match elements of the arrays at a path by a field, e.g. 'spec.containers=name' (repeatable)",
+	},
+	&cli.BoolFlag{
+		Name:  "expand-properties",
+		Usage: "nest dotted keys of .properties files into objects",
+	},
+	&cli.StringFlag{
+		Name:  "input-format",
+		Usage: "format of both inputs instead of detecting it (" + strings.Join(code.InputFormats(), ", ") + ")",
+	},
+	&cli.StringFlag{
+		Name:  "format1",
+		Usage: "format of the first input, overrides --input-format",
+	},
+	&cli.StringFlag{
+		Name:  "format2",
+		Usage: "format of the second input, overrides --input-format",
+	},
+	&cli.BoolFlag{
+		Name:  "three-way",
+		Usage: "compare base, ours and theirs files and show where each key changed",
+	},
+	&cli.BoolFlag{
+		Name:  "matrix",
+		Usage: "show every key of two or more files side by side (formats: stylish, markdown, json)",
+	},
+	&cli.StringSliceFlag{
+		Name:  "ignore",
+		Usage: "leave out a path, e.g. 'metadata.resourceVersion', '**.updatedAt' or '/build/timestamp' (repeatable)",
+	},
+	&cli.StringFlag{
+		Name:  "ignore-file",
+		Usage: "read paths to leave out from `FILE`, one per line, # starts a comment",
+	},
+	&cli.StringSliceFlag{
+		Name:  "only",
+		Usage: "compare only the subtree at a path, e.g. 'spec.template' or '/dependencies' (repeatable)",
+	},
+	&cli.StringFlag{
+		Name:  "float-tolerance",
+		Usage: "treat numbers this close as equal, absolute (1e-9), relative (0.1%) or both (1e-9,0.1%)",
+	},
+	&cli.BoolFlag{
+		Name:  "loose",
+		Usage: "report values that differ only in type, like \"8080\" and 8080, as equivalent",
+	},
+	&cli.StringSliceFlag{
+		Name:  "loose-rule",
+		Usage: "enable one loose rule instead of all of them (" + strings.Join(looseRules(), ", ") + ") (repeatable)",
+	},
+	&cli.BoolFlag{
+		Name:  "fail-on-type-change",
+		Usage: "fail when a value changes between an object, an array and a scalar",
+	},
+	&cli.BoolFlag{
+		Name:    "quiet",
+		Aliases: []string{"q"},
+		Usage:   "print nothing, only report through the exit status whether the files differ",
+	},
+}
+
+func looseRules() []string {
+	var names []string
+	for _, rule := range code.Coercions() {
+		names = append(names, string(rule))
+	}
+	return names
+}
+
+// Exit statuses, as with diff(1) and cmp(1).
+const (
+	exitSame    = 0
+	exitDiffers = 1
+	exitError   = 2
+)
+
+// stdinPlaceholder stands in for a "-" path while urfave/cli parses the
+// command line: it stops collecting arguments at a lone "-" and drops the
+// ones after it.
+const stdinPlaceholder = "\x00stdin"
+
+func protectStdinArgs(args []string) []string {
+	protected := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "-" {
+			arg = stdinPlaceholder
+		}
+		protected[i] = arg
+	}
+	return protected
+}
+
+func restoreStdinArgs(args []string) []string {
+	for i, arg := range args {
+		if arg == stdinPlaceholder {
+			args[i] = "-"
+		}
+	}
+	return args
+}
+
+// optionsFromFlags collects the diff settings given on the command line.
+func optionsFromFlags(c *cli.Command) (code.Options, error) {
+	options := code.Options{
+		Format:           c.String("format"),
+		ArrayDiff:        code.ArrayDiffMode(c.String("array-diff")),
+		ExpandProperties: c.Bool("expand-properties"),
+		InputFormats:     []string{inputFormat(c, "format1"), inputFormat(c, "format2")},
+		ThreeWay:         c.Bool("three-way"),
+		FailOnTypeChange: c.Bool("fail-on-type-change"),
+		Matrix:           c.Bool("matrix"),
+	}
+	for len(options.InputFormats) < c.Args().Len() {
+		options.InputFormats = append(options.InputFormats, c.String("input-format"))
+	}
+	for _, spec := range c.StringSlice("array-key") {
+		key, err := code.ParseArrayKey(spec)
+		if err != nil {
+			return options, err
+		}
+		options.ArrayKeys = append(options.ArrayKeys, key)
+	}
+	if path := c.String("ignore-file"); path != "" {
+		patterns, err := code.ReadIgnoreFile(path)
+		if err != nil {
+			return options, err
+		}
+		options.Ignore = append(options.Ignore, patterns...)
+	}
+	options.Ignore = append(options.Ignore, c.StringSlice("ignore")...)
+	options.Only = c.StringSlice("only")
+	if spec := c.String("float-tolerance"); spec != "" {
+		tolerance, err := code.ParseTolerance(spec)
+		if err != nil {
+			return options, err
+		}
+		options.FloatTolerance = tolerance
+	}
+	for _, rule := range c.StringSlice("loose-rule") {
+		options.Loose = append(options.Loose, code.Coercion(rule))
+	}
+	if c.Bool("loose") && len(options.Loose) == 0 {
+		options.Loose = code.Coercions()
+	}
+	return options, nil
+}
+
+// inputFormat returns the per-input format flag, falling back to --input-format.
+func inputFormat(c *cli.Command, name string) string {
+	if f := c.String(name); f != "" {
+		return f
+	}
+	return c.String("input-format")
+}
+
+// applyCommand writes a file with a JSON Patch or JSON Merge Patch applied,
+// in the file's own format.
+var applyCommand = &cli.Command{
+	Name:      "apply",
+	Usage:     "Applies a JSON Patch or JSON Merge Patch to a configuration file.",
+	ArgsUsage: "<file> <patch>",
+	Flags:     []cli.Flag{outputFlag},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.Args().Len() != 2 {
+			return fmt.Errorf("a file and a patch are required")
+		}
+		args := restoreStdinArgs(c.Args().Slice())
+		opts := []code.Option{code.WithInputFormats(inputFormat(c, "format1"))}
+		if c.Bool("expand-properties") {
+			opts = append(opts, code.WithExpandProperties())
+		}
+		out, err := code.ApplyPatchFiles(args[0], args[1], opts...)
+		if err != nil {
+			return err
+		}
+		return writeOutput(c, out)
+	},
+}
+
+// mergeCommand writes the structural merge of two files derived from a
+// common base, in the base file's format. It exits with exitDiffers when
+// conflict markers are left in the result.
+var mergeCommand = &cli.Command{
+	Name:      "merge",
+	Usage:     "Merges the changes two configuration files made to their common base.",
+	ArgsUsage: "<base> <ours> <theirs>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "strategy",
+			Usage: "how to resolve keys changed differently on both sides (markers, ours, theirs, fail)",
+			Value: string(code.MergeMarkers),
+		},
+		outputFlag,
+	},
+	Action: func(ctx context.Context, c *cli.Command) error {
+		if c.Args().Len() != 3 {
+			return fmt.Errorf("base, ours and theirs files are required")
+		}
+		args := restoreStdinArgs(c.Args().Slice())
+		opts := []code.Option{
+			code.WithInputFormats(inputFormat(c, "format1"), inputFormat(c, "format2"), c.String("input-format")),
+			code.WithMergeStrategy(code.MergeStrategy(c.String("strategy"))),
+		}
+		if c.Bool("expand-properties") {
+			opts = append(opts, code.WithExpandProperties())
+		}
+		out, conflicts, err := code.MergeFiles(args[0], args[1], args[2], opts...)
+		if err != nil {
+			return err
+		}
+		if err := writeOutput(c, out); err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			fmt.Fprintf(os.Stderr, "%d merge conflicts: %s\n", len(conflicts), strings.Join(conflicts, ", "))
+			return errConflicts
+		}
+		return nil
+	},
+}
+
+// errConflicts is returned by merge when the written document still holds
+// conflict markers; like git merge-file, gendiff then exits with
+// exitDiffers rather than exitError.
+var errConflicts = errors.New("unresolved merge conflicts")
+
+// diffDirs prints the file by file report of two directory trees, followed by
+// the summary line on stderr so that machine-readable output stays valid.
+// It reports whether any file was changed, added or removed.
+func diffDirs(ctx context.Context, dir1, dir2 string, options code.Options, quiet bool) (bool, error) {
+	files, summary, err := code.DiffDirs(ctx, dir1, dir2, code.WithOptions(options))
+	if err != nil {
+		return false, err
+	}
+	out, err := code.FormatDir(files, options.Format)
+	if err != nil {
+		return false, err
+	}
+	if !quiet {
+		fmt.Println(out)
+		fmt.Fprintln(os.Stderr, summary)
+	}
+	return code.HasFileChanges(files), nil
+}
+
+var outputFlag = &cli.StringFlag{
+	Name:    "output",
+	Aliases: []string{"o"},
+	Usage:   "write the result to `PATH` instead of stdout",
+}
+
+// writeOutput writes a subcommand's result to --output or stdout.
+func writeOutput(c *cli.Command, out []byte) error {
+	if path := c.String("output"); path != "" {
+		return os.WriteFile(path, out, 0o644)
+	}
+	_, err := os.Stdout.Write(out)
+	return err
 }
 
 func main() {
+	var differs bool
 	command := &cli.Command{
-		Name:  "gendiff",
-		Usage: "Compares two configuration files and shows a difference.",
-		Flags: flags,
+		Name:     "gendiff",
+		Usage:    "Compares two configuration files, or two directories of them, and shows a difference. Use - to read a file from stdin.",
+		Flags:    flags,
+		Commands: []*cli.Command{applyCommand, mergeCommand},
 		Action: func(ctx context.Context, c *cli.Command) error {
 			if c.Args().Len() == 0 {
 				return fmt.Errorf("file paths are required")
 			}
-			paths := c.Args().Slice()
-			format := c.String("format")
-			out, err := parsers.ParseByPaths(paths, format)
+			paths := restoreStdinArgs(c.Args().Slice())
+			options, err := optionsFromFlags(c)
 			if err != nil {
 				return err
 			}
-			fmt.Println(out)
+			quiet := c.Bool("quiet")
+			if len(paths) == 2 && code.IsDir(paths[0]) && code.IsDir(paths[1]) && !options.ThreeWay && !options.Matrix {
+				differs, err = diffDirs(ctx, paths[0], paths[1], options, quiet)
+				return err
+			}
+			var out string
+			out, differs, err = parsers.CompareByPaths(paths, options.Format, code.WithOptions(options))
+			if err != nil {
+				return err
+			}
+			if !quiet {
+				fmt.Println(out)
+			}
 			return nil
 		},
 	}
-	if err := command.Run(context.Background(), os.Args); err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+	err := command.Run(context.Background(), protectStdinArgs(os.Args))
+	if errors.Is(err, errConflicts) {
+		os.Exit(exitDiffers)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(exitError)
+	}
+	if differs {
+		os.Exit(exitDiffers)
 	}
+	os.Exit(exitSame)
 }
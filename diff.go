@@ -3,62 +3,116 @@ package code
 import (
 	"code/internal/formatters"
 	"code/internal/models"
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 // GenDiff generates a formatted diff string comparing two configuration files by their paths.
 // This is the main exported function for external use.
 //
 // Parameters:
-//   - filepath1: path to the first configuration file
-//   - filepath2: path to the second configuration file
-//   - format: output format ("stylish", "plain", or "json")
+//   - filepath1: path to the first configuration file, "-" for stdin
+//   - filepath2: path to the second configuration file, "-" for stdin
+//   - format: name of a registered output format, see Formats; directories
+//     support only those listed by FormatDir
+//   - opts: optional behaviour settings, e.g. WithArrayDiff(ArrayDiffLCS)
 //
 // Returns:
 //   - formatted diff string
 //   - error if file reading, parsing, or formatting fails
-func GenDiff(filepath1, filepath2, format string) (string, error) {
-	// Read files
-	data1, err := os.ReadFile(filepath1)
+func GenDiff(filepath1, filepath2, format string, opts ...Option) (string, error) {
+	opts = append([]Option{WithFormat(format)}, opts...)
+	return GenDiffWithOptions(context.Background(), filepath1, filepath2, opts...)
+}
+
+// GenDiffWithOptions is GenDiff with every setting, including the output
+// format, given as an Option. It stops early with ctx.Err() once ctx is
+// done. When a and b are both directories their files are compared pair by
+// pair and rendered with FormatDir, see DiffDirs.
+//
+// Parameters:
+//   - ctx: context checked between reading, parsing and formatting
+//   - a: path to the first configuration file or directory, "-" for stdin
+//   - b: path to the second configuration file or directory, "-" for stdin
+//   - opts: settings such as WithFormat("plain") or WithOptions(o)
+func GenDiffWithOptions(ctx context.Context, a, b string, opts ...Option) (string, error) {
+	options, err := newOptions(opts)
 	if err != nil {
 		return "", err
 	}
-	data2, err := os.ReadFile(filepath2)
+	if IsDir(a) && IsDir(b) {
+		files, _, err := DiffDirs(ctx, a, b, WithOptions(options))
+		if err != nil {
+			return "", err
+		}
+		return formatters.FormatDir(files, options.Format)
+	}
+	nodes, err := DiffFiles(ctx, a, b, WithOptions(options))
 	if err != nil {
 		return "", err
 	}
-
-	// Detect formats
-	format1, err := detectFormat(filepath1)
-	if err != nil {
+	if err := ctx.Err(); err != nil {
 		return "", err
 	}
-	format2, err := detectFormat(filepath2)
+	return formatters.Format(nodes, options.Format)
+}
+
+// DiffFiles reads, decodes and compares the files at a and b and returns
+// their diff tree without formatting it, e.g. to check it with HasChanges.
+// Directories are compared with DiffDirs instead. It stops early with
+// ctx.Err() once ctx is done.
+func DiffFiles(ctx context.Context, a, b string, opts ...Option) ([]DiffNode, error) {
+	options, err := newOptions(opts)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if a == stdinPath && b == stdinPath {
+		return nil, fmt.Errorf("only one file can be read from stdin")
+	}
+	if IsDir(a) || IsDir(b) {
+		if !IsDir(a) || !IsDir(b) {
+			return nil, fmt.Errorf("cannot compare a directory with a file")
+		}
+		return nil, fmt.Errorf("%s and %s are directories, compare them with DiffDirs", a, b)
 	}
 
-	// Create FileData structures
-	filesData := []models.FileData{
-		{Content: data1, Format: format1},
-		{Content: data2, Format: format2},
+	// Read files and resolve their formats
+	filesData := make([]models.FileData, 0, 2)
+	for i, path := range []string{a, b} {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		fd, err := readFile(path, options.inputFormat(i))
+		if err != nil {
+			return nil, err
+		}
+		filesData = append(filesData, fd)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	return genDiffFromData(filesData, format)
+	maps, err := decodeFiles(filesData, options)
+	if err != nil {
+		return nil, err
+	}
+	nodes := buildDiffTree(maps[0], maps[1], nil, options)
+	return nodes, checkTypeChanges(nodes, options)
 }
 
+// detectFormat returns the registered input format whose extension the path
+// ends with. Dotenv files are also recognised by their conventional names
+// such as .env.example or .env.production.
 func detectFormat(path string) (string, error) {
-	formats := []string{".json", ".yaml", ".yml"}
-	for _, f := range formats {
-		if strings.HasSuffix(path, f) {
-			return f, nil
-		}
+	if format, ok := formatByExtension(path); ok {
+		return format, nil
+	}
+	if strings.HasPrefix(filepath.Base(path), ".env.") {
+		return inputDotenv, nil
 	}
 	return "", fmt.Errorf("format has no support")
 }
@@ -66,7 +120,8 @@ func detectFormat(path string) (string, error) {
 // GenDiffFromData generates a formatted diff string comparing two configuration files.
 // It accepts a slice of FileData containing file contents and their formats,
 // and a format string specifying the output format.
-// The function parses each file according to its format (JSON or YAML),
+// The function parses each file with the parser registered for its format
+// (JSON, YAML, TOML, INI, Java properties, dotenv or a custom one),
 // compares their key-value pairs recursively, and returns a formatted string.
 //
 // Supported output formats:
@@ -76,20 +131,45 @@ func detectFormat(path string) (string, error) {
 //
 // The output is sorted alphabetically by key names at each level.
 // Returns an error if file parsing or formatting fails.
-func genDiffFromData(filesData []models.FileData, format string) (string, error) {
-	maps := make([]map[string]any, len(filesData))
-	for i, fd := range filesData {
-		maps[i] = make(map[string]any)
-		if err := unmarshalFile(fd.Content, fd.Format, &maps[i]); err != nil {
-			return "", err
-		}
+func genDiffFromData(filesData []models.FileData, format string, opts ...Option) (string, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return "", err
+	}
+
+	maps, err := decodeFiles(filesData, options)
+	if err != nil {
+		return "", err
 	}
 
 	old, new := maps[0], maps[1]
-	diffTree := buildDiffTree(old, new)
+	diffTree := buildDiffTree(old, new, nil, options)
 	return formatters.Format(diffTree, format)
 }
 
+// decodeFiles parses every file according to its format.
+func decodeFiles(filesData []models.FileData, options Options) ([]map[string]any, error) {
+	maps := make([]map[string]any, len(filesData))
+	for i, fd := range filesData {
+		name, _, err := lookupParser(fd.Format)
+		if err == nil {
+			maps[i], err = parseFile(fd.Content, name)
+		}
+		if err != nil {
+			if fd.Path != "" {
+				return nil, fmt.Errorf("%s: %w", fd.Path, err)
+			}
+			return nil, err
+		}
+		if name == inputProperties && options.ExpandProperties {
+			if maps[i], err = expandDottedKeys(maps[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return maps, nil
+}
+
 // buildDiffTree recursively builds a diff tree comparing two maps.
 // It analyzes the keys present in both maps and creates a slice of DiffNode
 // representing the differences. The function handles:
@@ -98,10 +178,15 @@ func genDiffFromData(filesData []models.FileData, format string) (string, error)
 //   - Changed values (same key, different non-map values)
 //   - Unchanged values (same key and value)
 //   - Nested structures (same key with map values in both - recursively compared)
+//   - Arrays (same key with list values in both - compared element by element)
 //
 // Keys are sorted alphabetically at each level to ensure consistent output.
+// The path holds the segments leading to the compared maps and is used to
+// look up per-path options such as array keys. Keys matching an ignore
+// pattern are skipped along with everything below them, and so are keys
+// outside the subtrees selected with Only, see Options.filterNode.
 // Returns a slice of DiffNode representing the complete diff tree.
-func buildDiffTree(old, new map[string]any) []models.DiffNode {
+func buildDiffTree(old, new map[string]any, path []string, opts Options) []models.DiffNode {
 	keys := make(map[string]struct{})
 	for k := range old {
 		keys[k] = struct{}{}
@@ -118,44 +203,91 @@ func buildDiffTree(old, new map[string]any) []models.DiffNode {
 
 	nodes := make([]models.DiffNode, 0, len(sortedKeys))
 	for _, key := range sortedKeys {
+		keyPath := appendPath(path, key)
+		if !opts.reachable(keyPath) {
+			continue
+		}
+
 		oldVal, inOld := old[key]
 		newVal, inNew := new[key]
 
-		node := models.DiffNode{Key: key}
-
+		var node models.DiffNode
 		switch {
+		case inOld != inNew && (oldVal == nil && newVal == nil) && opts.coerces(CoerceNullAbsent):
+			node = models.DiffNode{Key: key, Type: models.NodeTypeEquivalent}
 		case inOld && !inNew:
-			node.Type = models.NodeTypeRemoved
-			node.OldValue = oldVal
-
+			node = models.DiffNode{Key: key, Type: models.NodeTypeRemoved, OldValue: oldVal}
 		case !inOld && inNew:
-			node.Type = models.NodeTypeAdded
-			node.NewValue = newVal
-
-		case inOld && inNew:
-			oldMap, oldIsMap := oldVal.(map[string]any)
-			newMap, newIsMap := newVal.(map[string]any)
-
-			if oldIsMap && newIsMap {
-				node.Type = models.NodeTypeNested
-				node.Children = buildDiffTree(oldMap, newMap)
-			} else if !valuesEqual(oldVal, newVal) {
-				node.Type = models.NodeTypeChanged
-				node.OldValue = oldVal
-				node.NewValue = newVal
-			} else {
-				node.Type = models.NodeTypeUnchanged
-				node.OldValue = oldVal
-			}
+			node = models.DiffNode{Key: key, Type: models.NodeTypeAdded, NewValue: newVal}
+		default:
+			node = diffValues(key, oldVal, newVal, keyPath, opts)
+		}
+		if node, ok := opts.filterNode(keyPath, node); ok {
+			nodes = append(nodes, node)
 		}
-
-		nodes = append(nodes, node)
 	}
 
 	return nodes
 }
 
-func valuesEqual(a, b any) bool {
+// diffValues compares two values stored under the same key (or array index)
+// and returns the node describing them. Maps present on both sides become
+// nested nodes, arrays present on both sides become array nodes with
+// element-level children, a map or array replaced by a value of another
+// kind becomes a type change, everything else is compared as a whole.
+func diffValues(key string, oldVal, newVal any, path []string, opts Options) models.DiffNode {
+	node := models.DiffNode{Key: key}
+
+	oldMap, oldIsMap := oldVal.(map[string]any)
+	newMap, newIsMap := newVal.(map[string]any)
+	oldList, oldIsList := oldVal.([]any)
+	newList, newIsList := newVal.([]any)
+
+	switch {
+	case oldIsMap && newIsMap:
+		node.Type = models.NodeTypeNested
+		node.OldValue = oldVal
+		node.NewValue = newVal
+		node.Children = buildDiffTree(oldMap, newMap, path, opts)
+	case oldIsList && newIsList:
+		node.Type = models.NodeTypeArray
+		node.OldValue = oldVal
+		node.NewValue = newVal
+		node.Children = buildArrayDiff(oldList, newList, path, opts)
+	case oldIsMap || newIsMap || oldIsList || newIsList:
+		node.Type = models.NodeTypeTypeChanged
+		node.OldValue = oldVal
+		node.NewValue = newVal
+		node.OldKind = models.KindOf(oldVal)
+		node.NewKind = models.KindOf(newVal)
+	case valuesEqual(oldVal, newVal, opts.strict()):
+		node.Type = models.NodeTypeUnchanged
+		node.OldValue = oldVal
+	case valuesEqual(oldVal, newVal, opts):
+		node.Type = models.NodeTypeEquivalent
+		node.OldValue = oldVal
+		node.NewValue = newVal
+	default:
+		node.Type = models.NodeTypeChanged
+		node.OldValue = oldVal
+		node.NewValue = newVal
+	}
+
+	return node
+}
+
+// appendPath returns a new path with the segment appended, leaving the
+// parent's backing array untouched for its siblings.
+func appendPath(path []string, segment string) []string {
+	next := make([]string, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, segment)
+}
+
+// valuesEqual compares two decoded values deeply. Numbers are compared by
+// value whatever their Go type, within opts.FloatTolerance, and scalars of
+// different types are related by the loose rules of opts.
+func valuesEqual(a, b any, opts Options) bool {
 	aMap, aIsMap := a.(map[string]any)
 	bMap, bIsMap := b.(map[string]any)
 	if aIsMap && bIsMap {
@@ -164,32 +296,46 @@ func valuesEqual(a, b any) bool {
 		}
 		for k, v := range aMap {
 			bv, ok := bMap[k]
-			if !ok || !valuesEqual(v, bv) {
+			if !ok || !valuesEqual(v, bv, opts) {
 				return false
 			}
 		}
 		return true
 	}
 
+	aList, aIsList := a.([]any)
+	bList, bIsList := b.([]any)
+	if aIsList || bIsList {
+		if !aIsList || !bIsList || len(aList) != len(bList) {
+			return false
+		}
+		for i := range aList {
+			if !valuesEqual(aList[i], bList[i], opts) {
+				return false
+			}
+		}
+		return true
+	}
+	if aIsMap || bIsMap {
+		return false
+	}
+	if equal, ok := numbersEqual(a, b, opts.FloatTolerance); ok {
+		return equal
+	}
+
+	return sameScalar(a, b) || scalarsEquivalent(a, b, opts)
+}
+
+// sameScalar compares two values with ==, or with reflect.DeepEqual when
+// their type is not comparable, e.g. a map with non-string keys from a
+// custom parser, on which == would panic.
+func sameScalar(a, b any) bool {
+	if a == nil || b == nil || !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return reflect.DeepEqual(a, b)
+	}
 	return a == b
 }
 
 func printDiff(sep, key string, val any) string {
 	return fmt.Sprintf("  %s%s: %v\n", sep, key, val)
 }
-
-func unmarshalFile(data []byte, format string, v any) error {
-	switch format {
-	case ".json":
-		if err := json.Unmarshal(data, v); err != nil {
-			return err
-		}
-	case ".yaml", ".yml":
-		if err := yaml.Unmarshal(data, v); err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unknown format")
-	}
-	return nil
-}
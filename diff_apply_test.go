@@ -0,0 +1,229 @@
+package code
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	doc := map[string]any{
+		"host": "hexlet.io",
+		"db":   map[string]any{"port": 5432, "user": "admin"},
+		"tags": []any{"a", "b"},
+		"a/b":  "slash",
+		"m~n":  "tilde",
+	}
+
+	tests := []struct {
+		name    string
+		patch   string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name:  "json patch",
+			patch: `[{"op": "replace", "path": "/db/port", "value": 6543}, {"op": "add", "path": "/tags/1", "value": "x"}, {"op": "remove", "path": "/host"}]`,
+			want: map[string]any{
+				"db":   map[string]any{"port": 6543, "user": "admin"},
+				"tags": []any{"a", "x", "b"},
+				"a/b":  "slash",
+				"m~n":  "tilde",
+			},
+		},
+		{
+			name:  "move copy and append",
+			patch: `[{"op": "move", "from": "/db/user", "path": "/user"}, {"op": "copy", "from": "/tags/0", "path": "/tags/-"}]`,
+			want: map[string]any{
+				"host": "hexlet.io",
+				"db":   map[string]any{"port": 5432},
+				"user": "admin",
+				"tags": []any{"a", "b", "a"},
+				"a/b":  "slash",
+				"m~n":  "tilde",
+			},
+		},
+		{
+			name:  "escaped pointers",
+			patch: `[{"op": "test", "path": "/a~1b", "value": "slash"}, {"op": "remove", "path": "/a~1b"}, {"op": "replace", "path": "/m~0n", "value": 1.5}]`,
+			want: map[string]any{
+				"host": "hexlet.io",
+				"db":   map[string]any{"port": 5432, "user": "admin"},
+				"tags": []any{"a", "b"},
+				"m~n":  1.5,
+			},
+		},
+		{
+			name:  "merge patch",
+			patch: `{"host": null, "db": {"user": null, "name": "app"}, "tags": ["c"]}`,
+			want: map[string]any{
+				"db":   map[string]any{"port": 5432, "name": "app"},
+				"tags": []any{"c"},
+				"a/b":  "slash",
+				"m~n":  "tilde",
+			},
+		},
+		{
+			name:  "whole document",
+			patch: `[{"op": "test", "path": "", "value": {"host": "hexlet.io", "db": {"port": 5432, "user": "admin"}, "tags": ["a", "b"], "a/b": "slash", "m~n": "tilde"}}, {"op": "replace", "path": "", "value": {"host": "example.com"}}]`,
+			want:  map[string]any{"host": "example.com"},
+		},
+		{
+			name:    "whole document must stay an object",
+			patch:   `[{"op": "replace", "path": "", "value": [1]}]`,
+			wantErr: "patched document is not an object",
+		},
+		{
+			name:    "failed test",
+			patch:   `[{"op": "remove", "path": "/host"}, {"op": "test", "path": "/db/port", "value": 1}]`,
+			wantErr: "operation 1 (test /db/port): test failed",
+		},
+		{
+			name:    "missing path",
+			patch:   `[{"op": "replace", "path": "/db/missing/x", "value": 1}]`,
+			wantErr: `member "missing" not found`,
+		},
+		{
+			name:    "index out of range",
+			patch:   `[{"op": "add", "path": "/tags/3", "value": 1}]`,
+			wantErr: "array index 3 out of range",
+		},
+		{
+			name:    "not a patch",
+			patch:   `"replace"`,
+			wantErr: "patch must be a JSON array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := ApplyPatch(doc, []byte(tt.patch))
+			if tt.wantErr != "" {
+				r.ErrorContains(err, tt.wantErr)
+				return
+			}
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+
+	// the input document is never modified
+	require.Equal(t, "hexlet.io", doc["host"])
+	require.Equal(t, []any{"a", "b"}, doc["tags"])
+}
+
+func TestApplyPatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		patch   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"host": "hexlet.io", "timeout": 50}`,
+			patch:   `[{"op": "replace", "path": "/timeout", "value": 20}]`,
+			want:    "{\n  \"host\": \"hexlet.io\",\n  \"timeout\": 20\n}\n",
+		},
+		{
+			name:    "yaml",
+			file:    "config.yml",
+			content: "host: hexlet.io\ndb:\n  port: 5432\n",
+			patch:   `{"db": {"port": 6543, "user": "admin"}}`,
+			want:    "db:\n  port: 6543\n  user: admin\nhost: hexlet.io\n",
+		},
+		{
+			name:    "yaml keeps timestamps",
+			file:    "dates.yaml",
+			content: "day: 2020-01-01\nat: 2020-01-01T10:00:00Z\nlocal: 2020-01-01 10:30:00\n",
+			patch:   `{"x": 1}`,
+			want:    "at: 2020-01-01T10:00:00Z\nday: 2020-01-01\nlocal: 2020-01-01 10:30:00\nx: 1\n",
+		},
+		{
+			name:    "toml keeps datetimes",
+			file:    "config.toml",
+			content: "released = 2024-05-01\n\n[db]\nport = 5432\n",
+			patch:   `[{"op": "add", "path": "/db/user", "value": "admin"}]`,
+			want:    "released = 2024-05-01\n\n[db]\nport = 5432\nuser = 'admin'\n",
+		},
+		{
+			name:    "toml datetimes replaced by strings",
+			file:    "dates.toml",
+			content: "at = 2020-01-01T10:00:00Z\nday = 2020-01-01\nname = 'x'\n",
+			patch:   `{"at": "2021-01-01T10:00:00Z", "day": "not a date", "name": "2020-01-02"}`,
+			want:    "at = 2021-01-01T10:00:00Z\nday = 'not a date'\nname = '2020-01-02'\n",
+		},
+		{
+			name:    "toml datetime replaced by json patch",
+			file:    "replace.toml",
+			content: "at = 2020-01-01T10:00:00Z\n",
+			patch:   `[{"op": "replace", "path": "/at", "value": "2021-01-01T10:00:00Z"}]`,
+			want:    "at = 2021-01-01T10:00:00Z\n",
+		},
+		{
+			name:    "ini keeps dotted keys",
+			file:    "config.ini",
+			content: "top.level = 1\n\n[server]\nlog.level = debug\nlevel = info\n",
+			patch:   `{"server": {"port": "8080"}}`,
+			want:    "top.level = 1\n\n[server]\nlevel = info\nlog.level = debug\nport = 8080\n",
+		},
+		{
+			name:    "dotenv",
+			file:    ".env",
+			content: "APP_ENV=development\n",
+			patch:   `{"APP_ENV": "production", "DEBUG": "1"}`,
+			want:    "APP_ENV=production\nDEBUG=1\n",
+		},
+		{
+			name:    "dotenv cannot hold objects",
+			file:    ".env",
+			content: "APP_ENV=development\n",
+			patch:   `{"db": {"port": 1}}`,
+			wantErr: "dotenv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := ApplyPatchFiles(write(tt.file, tt.content), write("patch.json", tt.patch))
+			if tt.wantErr != "" {
+				r.ErrorContains(err, tt.wantErr)
+				return
+			}
+			r.NoError(err)
+			r.Equal(tt.want, string(got))
+		})
+	}
+}
+
+func TestApplyPatchFilesStdin(t *testing.T) {
+	r := require.New(t)
+
+	origStdin := stdin
+	t.Cleanup(func() { stdin = origStdin })
+	stdin = strings.NewReader(`{"follow": null}`)
+
+	got, err := ApplyPatchFiles("testdata/fixture/file1.json", "-")
+	r.NoError(err)
+	r.Equal("{\n  \"host\": \"hexlet.io\",\n  \"proxy\": \"123.234.53.22\",\n  \"timeout\": 50\n}\n", string(got))
+
+	_, err = ApplyPatchFiles("-", "-")
+	r.Error(err)
+}
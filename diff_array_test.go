@@ -0,0 +1,329 @@
+package code
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffArrays(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []models.FileData
+		format string
+		want   string
+	}{
+		{
+			name: "identical arrays",
+			files: []models.FileData{
+				{Content: []byte(`{"tags": ["a", "b"]}`), Format: ".json"},
+				{Content: []byte(`{"tags": ["a", "b"]}`), Format: ".json"},
+			},
+			format: "stylish",
+			want: `{
+    tags: [
+        a
+        b
+    ]
+}`,
+		},
+		{
+			name: "element changed, added and removed",
+			files: []models.FileData{
+				{Content: []byte(`{"a": [1, 2], "b": [1, 2, 3]}`), Format: ".json"},
+				{Content: []byte(`{"a": [1, 5, 6], "b": [1]}`), Format: ".json"},
+			},
+			format: "stylish",
+			want: `{
+    a: [
+        1
+      - 2
+      + 5
+      + 6
+    ]
+    b: [
+        1
+      - 2
+      - 3
+    ]
+}`,
+		},
+		{
+			name: "nested objects inside arrays",
+			files: []models.FileData{
+				{Content: []byte(`{"servers": [{"host": "a"}, {"host": "b"}]}`), Format: ".json"},
+				{Content: []byte(`{"servers": [{"host": "a"}, {"host": "c"}, {"host": "d"}]}`), Format: ".json"},
+			},
+			format: "stylish",
+			want: `{
+    servers: [
+        {
+            host: a
+        }
+        {
+          - host: b
+          + host: c
+        }
+      + {
+            host: d
+        }
+    ]
+}`,
+		},
+		{
+			name: "plain paths use indices",
+			files: []models.FileData{
+				{Content: []byte(`{"servers": [{"host": "a"}, {"host": "b"}], "ports": [80, 443]}`), Format: ".json"},
+				{Content: []byte(`{"servers": [{"host": "a"}, {"host": "c"}, {"host": "d"}], "ports": [80]}`), Format: ".json"},
+			},
+			format: "plain",
+			want: `Property 'ports[1]' was removed (old index)
+Property 'servers[1].host' was updated. From 'b' to 'c'
+Property 'servers[2]' was added with value: [complex value]`,
+		},
+		{
+			name: "array replaced by scalar",
+			files: []models.FileData{
+				{Content: []byte(`{"a": [1, 2]}`), Format: ".json"},
+				{Content: []byte(`{"a": "none"}`), Format: ".json"},
+			},
+			format: "plain",
+			want:   "Property 'a' changed type from array to string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := genDiffFromData(tt.files, tt.format)
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestGenDiffArraysJSONFormat(t *testing.T) {
+	files := []models.FileData{
+		{Content: []byte(`{"a": [1, {"b": 2}]}`), Format: ".json"},
+		{Content: []byte(`{"a": [1, {"b": 3}, 4]}`), Format: ".json"},
+	}
+	want := `{
+  "a": {
+    "type": "array",
+    "children": [
+      {"key": "0", "newIndex": 0, "type": "unchanged", "value": 1},
+      {"key": "1", "newIndex": 1, "type": "nested", "children": {
+        "b": {"type": "changed", "oldValue": 2, "newValue": 3}
+      }},
+      {"key": "2", "newIndex": 2, "type": "added", "value": 4}
+    ]
+  }
+}`
+
+	r := require.New(t)
+
+	got, err := genDiffFromData(files, "json")
+	r.NoError(err)
+
+	var gotJSON, wantJSON any
+	r.NoError(json.Unmarshal([]byte(got), &gotJSON))
+	r.NoError(json.Unmarshal([]byte(want), &wantJSON))
+	r.Equal(wantJSON, gotJSON)
+}
+
+func TestGenDiffArraysLCS(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []models.FileData
+		format string
+		want   string
+	}{
+		{
+			name: "insertion at the top",
+			files: []models.FileData{
+				{Content: []byte(`{"a": [1, 2, 3, 4]}`), Format: ".json"},
+				{Content: []byte(`{"a": [0, 1, 2, 3, 4]}`), Format: ".json"},
+			},
+			format: "plain",
+			want:   "Property 'a[0]' was added with value: 0",
+		},
+		{
+			name: "deletion in the middle",
+			files: []models.FileData{
+				{Content: []byte(`{"a": ["x", "y", "z"]}`), Format: ".json"},
+				{Content: []byte(`{"a": ["x", "z"]}`), Format: ".json"},
+			},
+			format: "stylish",
+			want: `{
+    a: [
+        x
+      - y
+        z
+    ]
+}`,
+		},
+		{
+			name: "removed elements keep their old index",
+			files: []models.FileData{
+				{Content: []byte(`{"a": ["x", "y", "z"]}`), Format: ".json"},
+				{Content: []byte(`{"a": ["w", "x", "z", "v"]}`), Format: ".json"},
+			},
+			format: "plain",
+			want: `Property 'a[0]' was added with value: 'w'
+Property 'a[1]' was removed (old index)
+Property 'a[3]' was added with value: 'v'`,
+		},
+		{
+			name: "replaced element is compared in place",
+			files: []models.FileData{
+				{Content: []byte(`{"a": [{"n": 1}, {"n": 2, "v": 1}, {"n": 3}]}`), Format: ".json"},
+				{Content: []byte(`{"a": [{"n": 0}, {"n": 1}, {"n": 2, "v": 2}, {"n": 3}]}`), Format: ".json"},
+			},
+			format: "plain",
+			want: `Property 'a[0]' was added with value: [complex value]
+Property 'a[2].v' was updated. From 1 to 2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := genDiffFromData(tt.files, tt.format, WithArrayDiff(ArrayDiffLCS))
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestGenDiffArraysLCSJSONFormat(t *testing.T) {
+	files := []models.FileData{
+		{Content: []byte(`{"a": ["x", "y", "z"]}`), Format: ".json"},
+		{Content: []byte(`{"a": ["x", "z", "w"]}`), Format: ".json"},
+	}
+	// "y" and "z" share key "1", in the old and the new list respectively
+	want := `{
+  "a": {
+    "type": "array",
+    "children": [
+      {"key": "0", "newIndex": 0, "type": "unchanged", "value": "x"},
+      {"key": "1", "oldIndex": 1, "type": "removed", "value": "y"},
+      {"key": "1", "newIndex": 1, "type": "unchanged", "value": "z"},
+      {"key": "2", "newIndex": 2, "type": "added", "value": "w"}
+    ]
+  }
+}`
+
+	r := require.New(t)
+
+	got, err := genDiffFromData(files, "json", WithArrayDiff(ArrayDiffLCS))
+	r.NoError(err)
+	r.JSONEq(want, got)
+}
+
+func TestGenDiffUnknownArrayMode(t *testing.T) {
+	files := []models.FileData{
+		{Content: []byte(`{}`), Format: ".json"},
+		{Content: []byte(`{}`), Format: ".json"},
+	}
+
+	_, err := genDiffFromData(files, "stylish", WithArrayDiff("zip"))
+	require.Error(t, err)
+}
+
+func TestGenDiffArraysKeyed(t *testing.T) {
+	old := `{"spec": {"containers": [
+		{"name": "web", "image": "nginx:1.25"},
+		{"name": "sidecar", "image": "envoy:1.0"}
+	]}}`
+	new := `{"spec": {"containers": [
+		{"name": "init", "image": "busybox"},
+		{"name": "sidecar", "image": "envoy:1.0"},
+		{"name": "web", "image": "nginx:1.27"}
+	]}}`
+
+	tests := []struct {
+		name   string
+		keys   []ArrayKey
+		format string
+		want   string
+	}{
+		{
+			name:   "matched by name regardless of order",
+			keys:   []ArrayKey{{Path: "spec.containers", Field: "name"}},
+			format: "plain",
+			want: `Property 'spec.containers[name=init]' was added with value: [complex value]
+Property 'spec.containers[name=web].image' was updated. From 'nginx:1.25' to 'nginx:1.27'`,
+		},
+		{
+			name:   "wildcard path",
+			keys:   []ArrayKey{{Path: "*.containers", Field: "name"}},
+			format: "stylish",
+			want: `{
+    spec: {
+        containers: [
+          + {
+                image: busybox
+                name: init
+            }
+            {
+                image: envoy:1.0
+                name: sidecar
+            }
+            {
+              - image: nginx:1.25
+              + image: nginx:1.27
+                name: web
+            }
+        ]
+    }
+}`,
+		},
+		{
+			name:   "missing field falls back to index",
+			keys:   []ArrayKey{{Path: "spec.containers", Field: "id"}},
+			format: "plain",
+			want: `Property 'spec.containers[0].image' was updated. From 'nginx:1.25' to 'busybox'
+Property 'spec.containers[0].name' was updated. From 'web' to 'init'
+Property 'spec.containers[2]' was added with value: [complex value]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			files := []models.FileData{
+				{Content: []byte(old), Format: ".json"},
+				{Content: []byte(new), Format: ".json"},
+			}
+			var opts []Option
+			for _, k := range tt.keys {
+				opts = append(opts, WithArrayKey(k.Path, k.Field))
+			}
+
+			got, err := genDiffFromData(files, tt.format, opts...)
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestParseArrayKey(t *testing.T) {
+	r := require.New(t)
+
+	key, err := ParseArrayKey("spec.containers=name")
+	r.NoError(err)
+	r.Equal(ArrayKey{Path: "spec.containers", Field: "name"}, key)
+
+	_, err = ParseArrayKey("spec.containers")
+	r.Error(err)
+	_, err = ParseArrayKey("spec.containers=")
+	r.Error(err)
+}
@@ -0,0 +1,116 @@
+package code
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestDiffDirs(t *testing.T) {
+	r := require.New(t)
+
+	dir1 := writeTree(t, map[string]string{
+		"app.yaml":      "host: hexlet.io\ntimeout: 50\n",
+		"old.json":      `{"legacy": true}`,
+		"sub/same.toml": "port = 1\n",
+		"README":        "not a config file",
+		"LICENSE":       "MIT",
+	})
+	dir2 := writeTree(t, map[string]string{
+		"app.yaml":      "host: hexlet.io\ntimeout: 20\n",
+		"sub/.env":      "A=1\n",
+		"sub/same.toml": "port = 1\n",
+		"notes.txt":     "not a config file either",
+		"LICENSE":       "Apache-2.0",
+	})
+
+	files, summary, err := DiffDirs(context.Background(), dir1, dir2)
+	r.NoError(err)
+	r.Equal(DirSummary{Changed: 2, Added: 2, Removed: 2, Unchanged: 1}, summary)
+	r.Equal("2 files changed, 2 added, 2 removed, 1 unchanged", summary.String())
+	r.True(HasFileChanges(files))
+
+	got, err := FormatDir(files, "plain")
+	r.NoError(err)
+	r.Equal(`File 'LICENSE' was changed
+File 'README' was removed
+File 'app.yaml' was changed:
+Property 'timeout' was updated. From 50 to 20
+File 'notes.txt' was added
+File 'old.json' was removed
+File 'sub/.env' was added`, got)
+
+	got, err = GenDiff(dir1, dir2, "stylish")
+	r.NoError(err)
+	r.Equal(`File 'LICENSE' was changed
+File 'README' was removed
+File 'app.yaml' was changed:
+{
+    host: hexlet.io
+  - timeout: 50
+  + timeout: 20
+}
+File 'notes.txt' was added
+File 'old.json' was removed
+File 'sub/.env' was added`, got)
+
+	got, err = GenDiff(dir1, dir2, "json")
+	r.NoError(err)
+	r.JSONEq(`[
+		{"path": "LICENSE", "status": "changed"},
+		{"path": "README", "status": "removed"},
+		{"path": "app.yaml", "status": "changed", "diff": {
+			"host": {"type": "unchanged", "value": "hexlet.io"},
+			"timeout": {"type": "changed", "oldValue": 50, "newValue": 20}
+		}},
+		{"path": "notes.txt", "status": "added"},
+		{"path": "old.json", "status": "removed"},
+		{"path": "sub/.env", "status": "added"},
+		{"path": "sub/same.toml", "status": "unchanged"}
+	]`, got)
+
+	for _, format := range []string{"jsonpatch", "mergepatch"} {
+		_, err = GenDiff(dir1, dir2, format)
+		r.ErrorContains(err, "does not support directory comparisons")
+	}
+}
+
+func TestDiffDirsErrors(t *testing.T) {
+	dir := writeTree(t, map[string]string{"broken.json": "{"})
+	other := writeTree(t, map[string]string{"broken.json": "{}"})
+
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{name: "invalid file", a: dir, b: other},
+		{name: "directory and file", a: dir, b: "testdata/fixture/file1.json"},
+		{name: "missing directory", a: filepath.Join(dir, "missing"), b: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenDiff(tt.a, tt.b, "stylish")
+			require.Error(t, err)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := DiffDirs(ctx, other, other)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,103 @@
+package code
+
+import (
+	"code/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffDotenv(t *testing.T) {
+	old := `# example settings
+export APP_ENV=development
+DB_URL="postgres://localhost/app"
+SECRET='s3cr3t#1' # quoted, then a comment
+GREETING=hello # inline comment
+CERT="-----BEGIN-----
+abc
+-----END-----"
+EMPTY=
+`
+	new := `APP_ENV=production
+DB_URL=postgres://db.internal/app
+SECRET='s3cr3t#1'
+GREETING="hello"
+CERT="-----BEGIN-----\nabc\n-----END-----"
+EMPTY=""
+NEW_FLAG=1
+`
+
+	files := []models.FileData{
+		{Content: []byte(old), Format: ".env"},
+		{Content: []byte(new), Format: ".env"},
+	}
+	want := `Property 'APP_ENV' was updated. From 'development' to 'production'
+Property 'DB_URL' was updated. From 'postgres://localhost/app' to 'postgres://db.internal/app'
+Property 'NEW_FLAG' was added with value: '1'`
+
+	r := require.New(t)
+
+	got, err := genDiffFromData(files, "plain")
+	r.NoError(err)
+	r.Equal(want, got)
+}
+
+func TestGenDiffDotenvErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing separator", content: "JUST_A_WORD\n"},
+		{name: "unterminated quote", content: "KEY=\"never closed\nMORE=1\n"},
+		{name: "text after a closing quote", content: "A=1\nKEY=\"x\" junk\n"},
+		{name: "comment right after a closing quote", content: "KEY='x'#c\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []models.FileData{
+				{Content: []byte(tt.content), Format: ".env"},
+				{Content: []byte(""), Format: ".env"},
+			}
+
+			_, err := genDiffFromData(files, "stylish")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "config/file.json", want: "json"},
+		{path: "file.yml", want: "yaml"},
+		{path: "Cargo.toml", want: "toml"},
+		{path: "app.ini", want: "ini"},
+		{path: "app.properties", want: "properties"},
+		{path: ".env", want: "dotenv"},
+		{path: "deploy/prod.env", want: "dotenv"},
+		{path: "deploy/.env.production", want: "dotenv"},
+		{path: ".env.json", want: "json"},
+		{path: "Makefile", wantErr: true},
+		{path: "CONFIG.YAML", want: "yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := detectFormat(tt.path)
+
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
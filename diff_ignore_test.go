@@ -0,0 +1,124 @@
+package code
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffIgnore(t *testing.T) {
+	old := map[string]any{
+		"metadata": map[string]any{"name": "web", "resourceVersion": "100", "a/b": 1},
+		"build":    map[string]any{"timestamp": "10:00", "version": "1.0"},
+		"items": []any{
+			map[string]any{"id": 1.0, "updatedAt": "mon"},
+			map[string]any{"id": 2.0, "updatedAt": "mon"},
+		},
+	}
+	new := map[string]any{
+		"metadata": map[string]any{"name": "api", "resourceVersion": "200", "a/b": 2},
+		"build":    map[string]any{"timestamp": "11:00", "version": "1.0"},
+		"items": []any{
+			map[string]any{"id": 1.0, "updatedAt": "tue"},
+			map[string]any{"id": 3.0, "updatedAt": "tue"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{
+			name: "nothing ignored",
+			want: `Property 'build.timestamp' was updated. From '10:00' to '11:00'
+Property 'items[0].updatedAt' was updated. From 'mon' to 'tue'
+Property 'items[1].id' was updated. From 2 to 3
+Property 'items[1].updatedAt' was updated. From 'mon' to 'tue'
+Property 'metadata.a/b' was updated. From 1 to 2
+Property 'metadata.name' was updated. From 'web' to 'api'
+Property 'metadata.resourceVersion' was updated. From '100' to '200'`,
+		},
+		{
+			name:     "dotted paths and wildcards",
+			patterns: []string{"metadata.resourceVersion", "items[*].updatedAt", "build.timestamp"},
+			want: `Property 'items[1].id' was updated. From 2 to 3
+Property 'metadata.a/b' was updated. From 1 to 2
+Property 'metadata.name' was updated. From 'web' to 'api'`,
+		},
+		{
+			name:     "double star matches at any depth",
+			patterns: []string{"**.updatedAt", "**.timestamp", "**.resourceVersion"},
+			want: `Property 'items[1].id' was updated. From 2 to 3
+Property 'metadata.a/b' was updated. From 1 to 2
+Property 'metadata.name' was updated. From 'web' to 'api'`,
+		},
+		{
+			name:     "json pointers",
+			patterns: []string{"/metadata/a~1b", "/items/1", "/build"},
+			want: `Property 'items[0].updatedAt' was updated. From 'mon' to 'tue'
+Property 'metadata.name' was updated. From 'web' to 'api'
+Property 'metadata.resourceVersion' was updated. From '100' to '200'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			nodes, err := Diff(old, new, WithIgnore(tt.patterns...))
+			r.NoError(err)
+			got, err := Format(nodes, "plain")
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+
+	_, err := Diff(old, new, WithIgnore(""))
+	require.Error(t, err)
+}
+
+func TestReadIgnoreFile(t *testing.T) {
+	r := require.New(t)
+
+	path := filepath.Join(t.TempDir(), ".gendiffignore")
+	r.NoError(os.WriteFile(path, []byte("# volatile fields\nmetadata.resourceVersion\n\n  /build/timestamp  \n"), 0o644))
+
+	patterns, err := ReadIgnoreFile(path)
+	r.NoError(err)
+	r.Equal([]string{"metadata.resourceVersion", "/build/timestamp"}, patterns)
+
+	_, err = ReadIgnoreFile(filepath.Join(t.TempDir(), "missing"))
+	r.Error(err)
+}
+
+func TestDiffIgnoreArrayElements(t *testing.T) {
+	old := map[string]any{"a": []any{1, 2, 3}}
+	new := map[string]any{"a": []any{9, 5, 3, 4}}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []any
+	}{
+		{name: "changed element", patterns: []string{"a[0]"}, want: []any{1, 5, 3, 4}},
+		{name: "added element", patterns: []string{"a[3]"}, want: []any{9, 5, 3}},
+		{name: "every element", patterns: []string{"a[*]"}, want: []any{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			nodes, err := Diff(old, new, WithIgnore(tt.patterns...))
+			r.NoError(err)
+			patch, err := Format(nodes, "jsonpatch")
+			r.NoError(err)
+			got, err := ApplyPatch(old, []byte(patch))
+			r.NoError(err)
+			r.Equal(map[string]any{"a": tt.want}, got)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package code
+
+import (
+	"code/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffINI(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []models.FileData
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "sections become nested objects",
+			files: []models.FileData{
+				{Content: []byte("; global\nname = app\n\n[database]\nhost = localhost\nport = 5432\n\n[cache]\nenabled: true\n"), Format: ".ini"},
+				{Content: []byte("name = app\n\n[database]\nhost = \"db.internal\"\nport = 5432\n# no cache\n"), Format: ".ini"},
+			},
+			format: "plain",
+			want: `Property 'cache' was removed
+Property 'database.host' was updated. From 'localhost' to 'db.internal'`,
+		},
+		{
+			name: "ini vs yaml",
+			files: []models.FileData{
+				{Content: []byte("[server]\nhost = localhost\nflag\n"), Format: ".ini"},
+				{Content: []byte("server:\n  host: localhost\n  flag: \"\"\n"), Format: ".yaml"},
+			},
+			format: "stylish",
+			want: `{
+    server: {
+        flag: 
+        host: localhost
+    }
+}`,
+		},
+		{
+			name: "unterminated section",
+			files: []models.FileData{
+				{Content: []byte("[server\nhost = localhost\n"), Format: ".ini"},
+				{Content: []byte(""), Format: ".ini"},
+			},
+			format:  "stylish",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := genDiffFromData(tt.files, tt.format)
+
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
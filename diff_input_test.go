@@ -0,0 +1,140 @@
+package code
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   [2]string
+		stdin   string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "yaml from stdin",
+			paths: [2]string{"-", "testdata/fixture/file1.json"},
+			stdin: "host: hexlet.io\ntimeout: 20\n",
+			opts:  []Option{WithInputFormats("yaml", "")},
+			want:  "{\n  + follow: false\n    host: hexlet.io\n  + proxy: 123.234.53.22\n  - timeout: 20\n  + timeout: 50\n}",
+		},
+		{
+			name:  "override for extension-less content",
+			paths: [2]string{"testdata/fixture/file1.json", "-"},
+			stdin: `{"host": "hexlet.io"}`,
+			opts:  []Option{WithInputFormats("", ".JSON")},
+			want:  "{\n  - follow: false\n    host: hexlet.io\n  - proxy: 123.234.53.22\n  - timeout: 50\n}",
+		},
+		{
+			name:  "stdin without a format is sniffed",
+			paths: [2]string{"-", "testdata/fixture/file1.json"},
+			stdin: "host = \"hexlet.io\"\n",
+			want:  "{\n  + follow: false\n    host: hexlet.io\n  + proxy: 123.234.53.22\n  + timeout: 50\n}",
+		},
+		{
+			name:    "stdin that is no known format",
+			paths:   [2]string{"-", "testdata/fixture/file1.json"},
+			stdin:   "just some words",
+			wantErr: true,
+		},
+		{
+			name:    "stdin twice",
+			paths:   [2]string{"-", "-"},
+			opts:    []Option{WithInputFormats("json", "json")},
+			wantErr: true,
+		},
+		{
+			name:    "unknown input format",
+			paths:   [2]string{"testdata/fixture/file1.json", "testdata/fixture/file2.json"},
+			opts:    []Option{WithInputFormats("xml", "")},
+			wantErr: true,
+		},
+		{
+			name:    "override that does not match the content",
+			paths:   [2]string{"testdata/fixture/file1.yaml", "testdata/fixture/file2.json"},
+			opts:    []Option{WithInputFormats("json", "")},
+			wantErr: true,
+		},
+	}
+
+	origStdin := stdin
+	t.Cleanup(func() { stdin = origStdin })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			stdin = strings.NewReader(tt.stdin)
+			got, err := GenDiff(tt.paths[0], tt.paths[1], "stylish", tt.opts...)
+
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestReadFileMisleadingExtension(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	yamlInJSON := filepath.Join(dir, "config.json")
+	r.NoError(os.WriteFile(yamlInJSON, []byte("host: hexlet.io\ntimeout: 50\n"), 0o644))
+
+	fd, err := readFile(yamlInJSON, "")
+	r.NoError(err)
+	r.Equal("yaml", fd.Format)
+
+	broken := filepath.Join(dir, "broken.json")
+	r.NoError(os.WriteFile(broken, []byte("just some words\n[broken\n"), 0o644))
+
+	_, err = readFile(broken, "")
+	r.ErrorContains(err, "cannot detect the format of "+broken)
+	for _, format := range sniffOrder {
+		r.ErrorContains(err, format+":")
+	}
+}
+
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "json", content: `{"a": [1, 2]}`, want: "json"},
+		{name: "toml", content: "[server]\nport = 8080\n", want: "toml"},
+		{name: "yaml", content: "server:\n  port: 8080\n", want: "yaml"},
+		{name: "ini", content: "[server]\nhost = localhost\n", want: "ini"},
+		{name: "yaml list is not an object", content: "- a\n- b\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := sniffFormat("config", []byte(tt.content), "")
+
+			if tt.wantErr {
+				r.ErrorContains(err, "cannot detect the format of config")
+				r.ErrorContains(err, "json:")
+				r.ErrorContains(err, "ini:")
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package code
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffJSONPatchFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []models.FileData
+		opts  []Option
+		want  string
+	}{
+		{
+			name: "add, remove and replace",
+			files: []models.FileData{
+				{Content: []byte(`{"a": 1, "b": 2, "c": {"d": true}}`), Format: ".json"},
+				{Content: []byte(`{"a": 1, "c": {"d": null}, "e": "new"}`), Format: ".json"},
+			},
+			want: `[
+  {"op": "remove", "path": "/b"},
+  {"op": "replace", "path": "/c/d", "value": null},
+  {"op": "add", "path": "/e", "value": "new"}
+]`,
+		},
+		{
+			name: "dates and times are strings",
+			files: []models.FileData{
+				{Content: []byte("at = 2020-01-01T10:00:00Z\n"), Format: ".toml"},
+				{Content: []byte("at = 2021-01-01T10:00:00Z\ndays = [2020-01-02]\n"), Format: ".toml"},
+			},
+			want: `[
+  {"op": "replace", "path": "/at", "value": "2021-01-01T10:00:00Z"},
+  {"op": "add", "path": "/days", "value": ["2020-01-02"]}
+]`,
+		},
+		{
+			name: "identical files",
+			files: []models.FileData{
+				{Content: []byte(`{"a": [1, 2]}`), Format: ".json"},
+				{Content: []byte(`{"a": [1, 2]}`), Format: ".json"},
+			},
+			want: `[]`,
+		},
+		{
+			name: "move of an equal value",
+			files: []models.FileData{
+				{Content: []byte(`{"old": {"x": 1}, "keep": 1}`), Format: ".json"},
+				{Content: []byte(`{"new": {"x": 1}, "keep": 1}`), Format: ".json"},
+			},
+			want: `[
+  {"op": "move", "from": "/old", "path": "/new"}
+]`,
+		},
+		{
+			name: "pointer escaping",
+			files: []models.FileData{
+				{Content: []byte(`{"a/b": 1, "m~n": {"c": 1}}`), Format: ".json"},
+				{Content: []byte(`{"a/b": 2, "m~n": {"c": 2}}`), Format: ".json"},
+			},
+			want: `[
+  {"op": "replace", "path": "/a~1b", "value": 2},
+  {"op": "replace", "path": "/m~0n/c", "value": 2}
+]`,
+		},
+		{
+			name: "array elements by index",
+			files: []models.FileData{
+				{Content: []byte(`{"a": [1, 2, 3], "b": [{"x": 1}]}`), Format: ".json"},
+				{Content: []byte(`{"a": [1], "b": [{"x": 2}, 5]}`), Format: ".json"},
+			},
+			want: `[
+  {"op": "remove", "path": "/a/1"},
+  {"op": "remove", "path": "/a/1"},
+  {"op": "replace", "path": "/b/0/x", "value": 2},
+  {"op": "add", "path": "/b/1", "value": 5}
+]`,
+		},
+		{
+			name: "array elements aligned by lcs",
+			files: []models.FileData{
+				{Content: []byte(`{"a": ["x", "y", "z"]}`), Format: ".json"},
+				{Content: []byte(`{"a": ["w", "x", "z", "q"]}`), Format: ".json"},
+			},
+			opts: []Option{WithArrayDiff(ArrayDiffLCS)},
+			want: `[
+  {"op": "add", "path": "/a/0", "value": "w"},
+  {"op": "remove", "path": "/a/2"},
+  {"op": "add", "path": "/a/3", "value": "q"}
+]`,
+		},
+		{
+			name: "keyed arrays are replaced",
+			files: []models.FileData{
+				{Content: []byte(`{"c": [{"name": "a", "v": 1}, {"name": "b", "v": 1}]}`), Format: ".json"},
+				{Content: []byte(`{"c": [{"name": "b", "v": 1}, {"name": "a", "v": 2}]}`), Format: ".json"},
+			},
+			opts: []Option{WithArrayKey("c", "name")},
+			want: `[
+  {"op": "replace", "path": "/c", "value": [{"name": "b", "v": 1}, {"name": "a", "v": 2}]}
+]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := genDiffFromData(tt.files, "jsonpatch", tt.opts...)
+			r.NoError(err)
+
+			var gotJSON, wantJSON any
+			r.NoError(json.Unmarshal([]byte(got), &gotJSON), "got should be valid JSON")
+			r.NoError(json.Unmarshal([]byte(tt.want), &wantJSON), "want should be valid JSON")
+			r.Equal(wantJSON, gotJSON)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffLoose(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		opts   []Option
+		want   string
+	}{
+		{
+			name:   "strict",
+			format: "plain",
+			want: `Property 'enabled' was updated. From 'true' to true
+Property 'name' was updated. From '' to null
+Property 'port' was updated. From '8080' to 8080
+Property 'ratio' was updated. From '0.5' to 0.5
+Property 'replicas' was updated. From '3' to 4
+Property 'timeout' was removed`,
+		},
+		{
+			name:   "all rules",
+			format: "plain",
+			opts:   []Option{WithLoose()},
+			want: `Property 'enabled' is equivalent after coercion. From 'true' to true
+Property 'name' is equivalent after coercion. From '' to null
+Property 'port' is equivalent after coercion. From '8080' to 8080
+Property 'ratio' is equivalent after coercion. From '0.5' to 0.5
+Property 'replicas' was updated. From '3' to 4
+Property 'timeout' is equivalent after coercion. From null to null`,
+		},
+		{
+			name:   "selected rules",
+			format: "plain",
+			opts:   []Option{WithLoose(CoerceStringNumber, CoerceNullAbsent)},
+			want: `Property 'enabled' was updated. From 'true' to true
+Property 'name' was updated. From '' to null
+Property 'port' is equivalent after coercion. From '8080' to 8080
+Property 'ratio' is equivalent after coercion. From '0.5' to 0.5
+Property 'replicas' was updated. From '3' to 4
+Property 'timeout' is equivalent after coercion. From null to null`,
+		},
+		{
+			name:   "stylish annotation",
+			format: "stylish",
+			opts:   []Option{WithLoose()},
+			want: `{
+    enabled: true (equivalent after coercion)
+    host: example.com
+    name:  (equivalent after coercion)
+    port: 8080 (equivalent after coercion)
+    ratio: 0.5 (equivalent after coercion)
+  - replicas: 3
+  + replicas: 4
+    timeout: null (equivalent after coercion)
+}`,
+		},
+		{
+			name:   "patches ignore equivalent values",
+			format: "jsonpatch",
+			opts:   []Option{WithLoose()},
+			want: `[
+  {
+    "op": "replace",
+    "path": "/replicas",
+    "value": 4
+  }
+]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := GenDiff("testdata/fixture/loose.yaml", "testdata/fixture/loose.json", tt.format, tt.opts...)
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+
+	_, err := GenDiff("testdata/fixture/loose.yaml", "testdata/fixture/loose.json", "plain", WithLoose("yes-no"))
+	require.Error(t, err)
+}
+
+func TestDiffLooseHasChanges(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"ports": []any{"80", "443"}, "debug": "FALSE"}
+	new := map[string]any{"ports": []any{80, 443}, "debug": false}
+
+	nodes, err := Diff(old, new, WithLoose())
+	r.NoError(err)
+	r.False(HasChanges(nodes))
+
+	got, err := Format(nodes, "json")
+	r.NoError(err)
+	r.JSONEq(`{
+		"debug": {"type": "equivalent", "oldValue": "FALSE", "newValue": false},
+		"ports": {"type": "array", "children": [
+			{"key": "0", "newIndex": 0, "type": "equivalent", "oldValue": "80", "newValue": 80},
+			{"key": "1", "newIndex": 1, "type": "equivalent", "oldValue": "443", "newValue": 443}
+		]}
+	}`, got)
+}
@@ -0,0 +1,82 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrix(t *testing.T) {
+	docs := []map[string]any{
+		{"db": map[string]any{"host": "localhost", "port": 5432}, "debug": true, "name": "app", "extra": map[string]any{}},
+		{"db": map[string]any{"host": "stg|db", "port": 5432}, "debug": false, "name": "app"},
+		{"db": map[string]any{"host": "prod", "port": 5432}, "name": "app", "replicas": []any{1, 2}},
+	}
+	names := []string{"dev.yaml", "staging.yaml", "prod.yaml"}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "stylish",
+			want: `  key       dev.yaml   staging.yaml  prod.yaml
+! db.host   localhost  stg|db        prod
+  db.port   5432       5432          5432
+! debug     true       false         (absent)
+! extra     {}         (absent)      (absent)
+  name      app        app           app
+! replicas  (absent)   (absent)      [1,2]`,
+		},
+		{
+			format: "markdown",
+			want: "| key | dev.yaml | staging.yaml | prod.yaml |\n" +
+				"|---|---|---|---|\n" +
+				"| **db.host** | `localhost` | `stg\\|db` | `prod` |\n" +
+				"| db.port | `5432` | `5432` | `5432` |\n" +
+				"| **debug** | `true` | `false` | *(absent)* |\n" +
+				"| **extra** | `{}` | *(absent)* | *(absent)* |\n" +
+				"| name | `app` | `app` | `app` |\n" +
+				"| **replicas** | *(absent)* | *(absent)* | `[1,2]` |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := require.New(t)
+
+			matrix, err := DiffMatrix(docs, names)
+			r.NoError(err)
+			got, err := FormatMatrix(matrix, tt.format)
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestMatrixJSON(t *testing.T) {
+	r := require.New(t)
+
+	matrix, err := DiffMatrix([]map[string]any{
+		{"a": map[string]any{"b": 1}, "c": "x"},
+		{"a": 1, "c": "x"},
+	}, []string{"one", "two"})
+	r.NoError(err)
+
+	got, err := FormatMatrix(matrix, "json")
+	r.NoError(err)
+	r.JSONEq(`{
+		"files": ["one", "two"],
+		"rows": [
+			{"path": "a", "values": {"two": 1}, "diverges": true},
+			{"path": "a.b", "values": {"one": 1}, "diverges": true},
+			{"path": "c", "values": {"one": "x", "two": "x"}, "diverges": false}
+		]
+	}`, got)
+
+	_, err = FormatMatrix(matrix, "plain")
+	r.ErrorContains(err, "does not support comparison matrices")
+
+	_, err = DiffMatrix([]map[string]any{{}}, []string{"one", "two"})
+	r.Error(err)
+}
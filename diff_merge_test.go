@@ -0,0 +1,184 @@
+package code
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	base := map[string]any{
+		"host":  "hexlet.io",
+		"port":  1,
+		"old":   "gone",
+		"db":    map[string]any{"user": "a", "pass": "x"},
+		"flags": []any{"a"},
+	}
+	ours := map[string]any{
+		"host":  "hexlet.io",
+		"port":  2,
+		"new":   true,
+		"db":    map[string]any{"user": "b", "pass": "x"},
+		"flags": []any{"a"},
+	}
+	theirs := map[string]any{
+		"host": "example.com",
+		"port": 3,
+		"db":   map[string]any{"user": "a", "pass": "y"},
+	}
+	resolved := map[string]any{
+		"host": "example.com",
+		"new":  true,
+		"db":   map[string]any{"user": "b", "pass": "y"},
+	}
+
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		port     any
+		wantErr  string
+	}{
+		{
+			name: "default marks conflicts",
+			port: map[string]any{"$conflict": map[string]any{"base": 1, "ours": 2, "theirs": 3}},
+		},
+		{name: "ours", strategy: MergeOurs, port: 2},
+		{name: "theirs", strategy: MergeTheirs, port: 3},
+		{name: "fail", strategy: MergeFail, wantErr: "1 merge conflicts: port"},
+		{name: "unknown strategy", strategy: "union", wantErr: "unknown merge strategy: union"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := Merge(base, ours, theirs, WithMergeStrategy(tt.strategy))
+			if tt.wantErr != "" {
+				r.EqualError(err, tt.wantErr)
+				return
+			}
+			r.NoError(err)
+
+			want := map[string]any{"port": tt.port}
+			for k, v := range resolved {
+				want[k] = v
+			}
+			r.Equal(want, got)
+		})
+	}
+}
+
+func TestMergeRemovedAgainstChanged(t *testing.T) {
+	r := require.New(t)
+
+	got, err := Merge(
+		map[string]any{"db": map[string]any{"port": 1}},
+		map[string]any{},
+		map[string]any{"db": map[string]any{"port": 2}},
+	)
+	r.NoError(err)
+	r.Equal(map[string]any{"db": map[string]any{"$conflict": map[string]any{
+		"base":   map[string]any{"port": 1},
+		"theirs": map[string]any{"port": 2},
+	}}}, got)
+
+	got, err = Merge(
+		map[string]any{"db": map[string]any{"port": 1}},
+		map[string]any{},
+		map[string]any{"db": map[string]any{"port": 2}},
+		WithMergeStrategy(MergeOurs),
+	)
+	r.NoError(err)
+	r.Equal(map[string]any{}, got)
+}
+
+func TestMergeKeepsFilteredKeys(t *testing.T) {
+	r := require.New(t)
+
+	base := map[string]any{"a": 1, "ts": "mon", "db": map[string]any{"port": 1}}
+	ours := map[string]any{"a": 2, "ts": "tue", "db": map[string]any{"port": 1}}
+	theirs := map[string]any{"a": 1, "ts": "mon", "db": map[string]any{"port": 2}}
+	want := map[string]any{"a": 2, "ts": "tue", "db": map[string]any{"port": 2}}
+
+	for _, opt := range []Option{WithIgnore("ts"), WithOnly("a")} {
+		got, err := Merge(base, ours, theirs, opt)
+		r.NoError(err)
+		r.Equal(want, got)
+	}
+}
+
+func TestMergeKeepsEquivalentEdits(t *testing.T) {
+	r := require.New(t)
+
+	base := map[string]any{"port": "8080", "opt": nil, "ratio": 0.3, "db": "x"}
+	ours := map[string]any{"port": 8080, "ratio": 0.30000001, "db": "x"}
+	theirs := map[string]any{"port": "8080", "opt": nil, "ratio": 0.3, "db": "y"}
+	want := map[string]any{"port": 8080, "ratio": 0.30000001, "db": "y"}
+
+	got, err := Merge(base, ours, theirs, WithLoose(), WithFloatTolerance(Tolerance{Abs: 1e-6}))
+	r.NoError(err)
+	r.Equal(want, got)
+}
+
+func TestMergeFiles(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		r.NoError(os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	base := write("base.yaml", "host: hexlet.io\ntimeout: 50\n")
+	ours := write("ours.json", `{"host": "hexlet.io", "timeout": 20}`)
+	theirs := write("theirs.toml", "host = 'example.com'\ntimeout = 50\n")
+
+	got, conflicts, err := MergeFiles(base, ours, theirs)
+	r.NoError(err)
+	r.Empty(conflicts)
+	r.Equal("host: example.com\ntimeout: 20\n", string(got))
+}
+
+func TestMergeFilesDatesIntoJSON(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		r.NoError(os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	base := write("base.json", `{"host": "hexlet.io"}`)
+	ours := write("ours.yaml", "host: hexlet.io\nreleased: 2024-01-01\n")
+	theirs := write("theirs.toml", "host = 'hexlet.io'\nat = 2024-01-02T10:00:00Z\n")
+
+	got, conflicts, err := MergeFiles(base, ours, theirs)
+	r.NoError(err)
+	r.Empty(conflicts)
+	r.Equal("{\n  \"at\": \"2024-01-02T10:00:00Z\",\n  \"host\": \"hexlet.io\",\n  \"released\": \"2024-01-01\"\n}\n", string(got))
+}
+
+func TestMergeFilesConflicts(t *testing.T) {
+	r := require.New(t)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		r.NoError(os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	base := write("base.json", `{"db": {"port": 1}, "host": "a"}`)
+	ours := write("ours.json", `{"db": {"port": 2}, "host": "b"}`)
+	theirs := write("theirs.json", `{"db": {"port": 3}, "host": "a"}`)
+
+	_, conflicts, err := MergeFiles(base, ours, theirs)
+	r.NoError(err)
+	r.Equal([]string{"db.port"}, conflicts)
+
+	got, conflicts, err := MergeFiles(base, ours, theirs, WithMergeStrategy(MergeOurs))
+	r.NoError(err)
+	r.Empty(conflicts)
+	r.Equal("{\n  \"db\": {\n    \"port\": 2\n  },\n  \"host\": \"b\"\n}\n", string(got))
+}
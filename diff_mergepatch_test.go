@@ -0,0 +1,87 @@
+package code
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffMergePatchFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []models.FileData
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "nested changes",
+			files: []models.FileData{
+				{Content: []byte(`{"a": "b", "c": {"d": "e", "f": "g"}, "same": {"x": 1}}`), Format: ".json"},
+				{Content: []byte(`{"a": "z", "c": {"d": "e"}, "same": {"x": 1}, "n": {"k": [1, null]}}`), Format: ".json"},
+			},
+			want: `{"a": "z", "c": {"f": null}, "n": {"k": [1, null]}}`,
+		},
+		{
+			name: "arrays are replaced",
+			files: []models.FileData{
+				{Content: []byte(`{"tags": ["a", "b"], "keep": [1]}`), Format: ".json"},
+				{Content: []byte(`{"tags": ["a", "c"], "keep": [1]}`), Format: ".json"},
+			},
+			want: `{"tags": ["a", "c"]}`,
+		},
+		{
+			name: "dates and times are strings",
+			files: []models.FileData{
+				{Content: []byte("at = 2020-01-01T10:00:00Z\nday = 2020-01-01\n"), Format: ".toml"},
+				{Content: []byte("at = 2021-01-01T10:00:00Z\nday = 2020-01-02\nlist = [2020-01-03]\n"), Format: ".toml"},
+			},
+			want: `{"at": "2021-01-01T10:00:00Z", "day": "2020-01-02", "list": ["2020-01-03"]}`,
+		},
+		{
+			name: "identical files",
+			files: []models.FileData{
+				{Content: []byte(`{"a": {"b": 1}}`), Format: ".json"},
+				{Content: []byte(`{"a": {"b": 1}}`), Format: ".json"},
+			},
+			want: `{}`,
+		},
+		{
+			name: "null value cannot be expressed",
+			files: []models.FileData{
+				{Content: []byte(`{"a": 1}`), Format: ".json"},
+				{Content: []byte(`{"a": null}`), Format: ".json"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "null inside a new object cannot be expressed",
+			files: []models.FileData{
+				{Content: []byte(`{}`), Format: ".json"},
+				{Content: []byte(`{"a": {"b": null}}`), Format: ".json"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := genDiffFromData(tt.files, "mergepatch")
+
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+
+			var gotJSON, wantJSON any
+			r.NoError(json.Unmarshal([]byte(got), &gotJSON), "got should be valid JSON")
+			r.NoError(json.Unmarshal([]byte(tt.want), &wantJSON), "want should be valid JSON")
+			r.Equal(wantJSON, gotJSON)
+		})
+	}
+}
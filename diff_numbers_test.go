@@ -0,0 +1,96 @@
+package code
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "types normalised",
+			want: `Property 'price' was updated. From 100.5 to 100.6
+Property 'share' was updated. From 0.30000000000000004 to 0.3`,
+		},
+		{
+			name: "absolute tolerance",
+			opts: []Option{WithFloatTolerance(Tolerance{Abs: 1e-9})},
+			want: "Property 'price' was updated. From 100.5 to 100.6",
+		},
+		{
+			name: "relative tolerance",
+			opts: []Option{WithFloatTolerance(Tolerance{Rel: 0.001})},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := GenDiff("testdata/fixture/numbers.json", "testdata/fixture/numbers.yaml", "plain", tt.opts...)
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestNumbersEqual(t *testing.T) {
+	tests := []struct {
+		name        string
+		a, b        any
+		tolerance   Tolerance
+		wantEqual   bool
+		wantNumeric bool
+	}{
+		{name: "int and float64", a: 1, b: 1.0, wantEqual: true, wantNumeric: true},
+		{name: "int64 and uint64", a: int64(42), b: uint64(42), wantEqual: true, wantNumeric: true},
+		{name: "large integers kept exact", a: int64(math.MaxInt64), b: int64(math.MaxInt64 - 1), wantNumeric: true},
+		{name: "negative and unsigned", a: -1, b: uint64(math.MaxUint64), wantNumeric: true},
+		{name: "fraction", a: 1, b: 1.5, wantNumeric: true},
+		{name: "within absolute tolerance", a: 1, b: 1.05, tolerance: Tolerance{Abs: 0.1}, wantEqual: true, wantNumeric: true},
+		{name: "outside relative tolerance", a: 100, b: 102, tolerance: Tolerance{Rel: 0.01}, wantNumeric: true},
+		{name: "not a number", a: 1, b: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, numeric := numbersEqual(tt.a, tt.b, tt.tolerance)
+			require.Equal(t, tt.wantEqual, equal)
+			require.Equal(t, tt.wantNumeric, numeric)
+		})
+	}
+}
+
+func TestParseTolerance(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Tolerance
+		wantErr bool
+	}{
+		{spec: "1e-9", want: Tolerance{Abs: 1e-9}},
+		{spec: "0.5%", want: Tolerance{Rel: 0.005}},
+		{spec: "0.01, 1%", want: Tolerance{Abs: 0.01, Rel: 0.01}},
+		{spec: "-1", wantErr: true},
+		{spec: "abc", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseTolerance(tt.spec)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
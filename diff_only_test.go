@@ -0,0 +1,174 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffOnly(t *testing.T) {
+	old := map[string]any{
+		"name": "app",
+		"spec": map[string]any{
+			"replicas": 1.0,
+			"template": map[string]any{
+				"containers": []any{
+					map[string]any{"image": "web:1", "name": "web"},
+				},
+			},
+		},
+		"dependencies": map[string]any{"lodash": "4.0.0"},
+	}
+	new := map[string]any{
+		"name": "service",
+		"spec": map[string]any{
+			"replicas": 3.0,
+			"template": map[string]any{
+				"containers": []any{
+					map[string]any{"image": "web:2", "name": "web"},
+				},
+			},
+		},
+		"dependencies": map[string]any{"lodash": "4.0.0", "react": "18.0.0"},
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		format   string
+		want     string
+	}{
+		{
+			name:     "plain keeps full paths",
+			patterns: []string{"spec.template"},
+			format:   "plain",
+			want:     "Property 'spec.template.containers[0].image' was updated. From 'web:1' to 'web:2'",
+		},
+		{
+			name:     "stylish keeps nesting",
+			patterns: []string{"spec.template.containers[*].image"},
+			format:   "stylish",
+			want: `{
+    spec: {
+        template: {
+            containers: [
+                {
+                  - image: web:1
+                  + image: web:2
+                }
+            ]
+        }
+    }
+}`,
+		},
+		{
+			name:     "several subtrees",
+			patterns: []string{"/dependencies", "name"},
+			format:   "plain",
+			want: `Property 'dependencies.react' was added with value: '18.0.0'
+Property 'name' was updated. From 'app' to 'service'`,
+		},
+		{
+			name:     "unchanged selection",
+			patterns: []string{"dependencies.lodash"},
+			format:   "plain",
+			want:     "",
+		},
+		{
+			name:     "missing path",
+			patterns: []string{"spec.selector"},
+			format:   "stylish",
+			want:     "{\n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			nodes, err := Diff(old, new, WithOnly(tt.patterns...))
+			r.NoError(err)
+			got, err := Format(nodes, tt.format)
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestDiffOnlyWithIgnore(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"spec": map[string]any{"image": "web:1", "updatedAt": "mon"}, "name": "app"}
+	new := map[string]any{"spec": map[string]any{"image": "web:2", "updatedAt": "tue"}, "name": "api"}
+
+	nodes, err := Diff(old, new, WithOnly("spec"), WithIgnore("**.updatedAt"))
+	r.NoError(err)
+	got, err := Format(nodes, "plain")
+	r.NoError(err)
+	r.Equal("Property 'spec.image' was updated. From 'web:1' to 'web:2'", got)
+}
+
+func TestDiffOnlyCutsValues(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"db": "postgres", "gone": map[string]any{"template": 1, "other": 2}}
+	new := map[string]any{
+		"db":   map[string]any{"host": "localhost", "port": 5432},
+		"spec": map[string]any{"replicas": 3, "template": map[string]any{"image": "web"}},
+	}
+
+	nodes, err := Diff(old, new, WithOnly("spec.template", "db.host", "*.template"))
+	r.NoError(err)
+	r.Equal([]DiffNode{
+		{
+			Key: "db", Type: NodeTypeTypeChanged,
+			OldValue: "postgres", NewValue: map[string]any{"host": "localhost"},
+			OldKind: "string", NewKind: "object",
+		},
+		{Key: "gone", Type: NodeTypeRemoved, OldValue: map[string]any{"template": 1}},
+		{Key: "spec", Type: NodeTypeAdded, NewValue: map[string]any{"template": map[string]any{"image": "web"}}},
+	}, nodes)
+
+	nodes, err = Diff(old, new, WithOnly("spec.selector"))
+	r.NoError(err)
+	r.Empty(nodes)
+}
+
+func TestDiffOnlyArrayElements(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"a": []any{1, 2, 3}, "b": []any{1}}
+	new := map[string]any{"a": []any{4, 5, 6}, "b": []any{2}}
+
+	nodes, err := Diff(old, new, WithOnly("a[2]"))
+	r.NoError(err)
+	patch, err := Format(nodes, "jsonpatch")
+	r.NoError(err)
+	got, err := ApplyPatch(old, []byte(patch))
+	r.NoError(err)
+	r.Equal(map[string]any{"a": []any{1, 2, 6}, "b": []any{1}}, got)
+
+	plain, err := Format(nodes, "plain")
+	r.NoError(err)
+	r.Equal("Property 'a[2]' was updated. From 3 to 6", plain)
+}
+
+func TestDiffOnlyArrayPlaceholders(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"items": []any{map[string]any{"n": 1, "x": 1}, map[string]any{"n": 2}}}
+	new := map[string]any{"items": []any{map[string]any{"n": 1, "x": 2}, map[string]any{"n": 3}}}
+
+	nodes, err := Diff(old, new, WithOnly("items[1]"))
+	r.NoError(err)
+	got, err := Format(nodes, "json")
+	r.NoError(err)
+	r.JSONEq(`{
+		"items": {"type": "array", "children": [
+			{"key": "0", "newIndex": 0, "type": "unchanged", "value": {"n": 1, "x": 1}},
+			{"key": "1", "newIndex": 1, "type": "nested", "children": {
+				"n": {"type": "changed", "oldValue": 2, "newValue": 3}
+			}}
+		]}
+	}`, got)
+}
@@ -0,0 +1,73 @@
+package code
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffWithOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     func() context.Context
+		opts    []Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "defaults to stylish",
+			want: "{\n  - follow: false\n    host: hexlet.io\n  - proxy: 123.234.53.22\n  - timeout: 50\n  + timeout: 20\n  + verbose: true\n}",
+		},
+		{
+			name: "format option",
+			opts: []Option{WithFormat("plain")},
+			want: `Property 'follow' was removed
+Property 'proxy' was removed
+Property 'timeout' was updated. From 50 to 20
+Property 'verbose' was added with value: true`,
+		},
+		{
+			name: "options struct with later overrides",
+			opts: []Option{
+				WithFormat("json"),
+				WithOptions(Options{Format: "plain", InputFormats: []string{"json", "json"}}),
+				WithArrayDiff(ArrayDiffLCS),
+			},
+			want: `Property 'follow' was removed
+Property 'proxy' was removed
+Property 'timeout' was updated. From 50 to 20
+Property 'verbose' was added with value: true`,
+		},
+		{
+			name: "canceled context",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx()
+			}
+
+			got, err := GenDiffWithOptions(ctx, "testdata/fixture/file1.json", "testdata/fixture/file2.json", tt.opts...)
+
+			if tt.wantErr != nil {
+				r.ErrorIs(err, tt.wantErr)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
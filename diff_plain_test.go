@@ -100,7 +100,7 @@ func TestGenDiffPlain(t *testing.T) {
 				{Content: []byte(`{"a": {"b": 1}}`), Format: ".json"},
 				{Content: []byte(`{"a": "string"}`), Format: ".json"},
 			},
-			want: "Property 'a' was updated. From [complex value] to 'string'",
+			want: "Property 'a' changed type from object to string",
 		},
 		{
 			name: "from simple to complex",
@@ -108,7 +108,7 @@ func TestGenDiffPlain(t *testing.T) {
 				{Content: []byte(`{"a": "string"}`), Format: ".json"},
 				{Content: []byte(`{"a": {"b": 1}}`), Format: ".json"},
 			},
-			want: "Property 'a' was updated. From 'string' to [complex value]",
+			want: "Property 'a' changed type from string to object",
 		},
 		{
 			name: "multiple changes",
@@ -135,7 +135,7 @@ Property 'common.setting5' was added with value: [complex value]
 Property 'common.setting6.doge.wow' was updated. From '' to 'so much'
 Property 'common.setting6.ops' was added with value: 'vops'
 Property 'group1.baz' was updated. From 'bas' to 'bars'
-Property 'group1.nest' was updated. From [complex value] to 'str'
+Property 'group1.nest' changed type from object to string
 Property 'group2' was removed
 Property 'group3' was added with value: [complex value]`,
 		},
@@ -0,0 +1,93 @@
+package code
+
+import (
+	"code/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffProperties(t *testing.T) {
+	old := `# application settings
+db.host=localhost
+db.port = 5432
+greeting: Hello\u0020World
+path=C:\\temp
+long.value = first \
+             second
+! legacy comment
+key\=with\:separators = yes
+`
+	new := `db.host=db.internal
+db.port=5432
+greeting Hello World
+path=C:\\temp
+long.value=first second
+key\=with\:separators = no
+`
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "flat keys",
+			want: `Property 'db.host' was updated. From 'localhost' to 'db.internal'
+Property 'key=with:separators' was updated. From 'yes' to 'no'`,
+		},
+		{
+			name: "expanded keys",
+			opts: []Option{WithExpandProperties()},
+			want: `Property 'db.host' was updated. From 'localhost' to 'db.internal'
+Property 'key=with:separators' was updated. From 'yes' to 'no'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			files := []models.FileData{
+				{Content: []byte(old), Format: ".properties"},
+				{Content: []byte(new), Format: ".properties"},
+			}
+
+			got, err := genDiffFromData(files, "plain", tt.opts...)
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestGenDiffPropertiesExpandedStylish(t *testing.T) {
+	files := []models.FileData{
+		{Content: []byte("db.host=localhost\ndb.port=5432\n"), Format: ".properties"},
+		{Content: []byte("db:\n  host: localhost\n  port: \"5433\"\n"), Format: ".yaml"},
+	}
+	want := `{
+    db: {
+        host: localhost
+      - port: 5432
+      + port: 5433
+    }
+}`
+
+	r := require.New(t)
+
+	got, err := genDiffFromData(files, "stylish", WithExpandProperties())
+	r.NoError(err)
+	r.Equal(want, got)
+}
+
+func TestGenDiffPropertiesExpandConflict(t *testing.T) {
+	files := []models.FileData{
+		{Content: []byte("db=main\ndb.host=localhost\n"), Format: ".properties"},
+		{Content: []byte(""), Format: ".properties"},
+	}
+
+	_, err := genDiffFromData(files, "stylish", WithExpandProperties())
+	require.Error(t, err)
+}
@@ -54,6 +54,14 @@ func TestGenDiff(t *testing.T) {
 			},
 			want: "{\n  - flag: true\n  + flag: false\n}",
 		},
+		{
+			name: "yaml keys that are not strings",
+			files: []models.FileData{
+				{Content: []byte("a: {1: x, true: y}\n"), Format: ".yaml"},
+				{Content: []byte("a: {1: x, true: z}\n"), Format: ".yaml"},
+			},
+			want: "{\n    a: {\n        1: x\n      - true: y\n      + true: z\n    }\n}",
+		},
 		{
 			name: "invalid json in first file",
 			files: []models.FileData{
@@ -242,3 +250,15 @@ func TestGenDiffNested(t *testing.T) {
 		})
 	}
 }
+
+func TestDiffNonComparableValues(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"a": map[any]any{1: "x"}, "b": map[any]any{1: "x"}}
+	new := map[string]any{"a": map[any]any{1: "x"}, "b": map[any]any{1: "y"}}
+
+	nodes, err := Diff(old, new)
+	r.NoError(err)
+	r.Equal(NodeTypeUnchanged, nodes[0].Type)
+	r.Equal(NodeTypeChanged, nodes[1].Type)
+}
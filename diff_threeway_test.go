@@ -0,0 +1,164 @@
+package code
+
+import (
+	"code/internal/formatters"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreeWayDiff(t *testing.T) {
+	base := map[string]any{
+		"host":    "hexlet.io",
+		"timeout": 50,
+		"port":    1,
+		"old":     "gone",
+		"list":    []any{1, 2},
+		"db":      map[string]any{"user": "a", "pass": "x"},
+		"cache":   map[string]any{"ttl": 10},
+	}
+	ours := map[string]any{
+		"host":    "hexlet.io",
+		"timeout": 20,
+		"port":    2,
+		"new":     true,
+		"list":    []any{1, 2},
+		"db":      map[string]any{"user": "b", "pass": "x"},
+		"cache":   map[string]any{"ttl": 10},
+	}
+	theirs := map[string]any{
+		"host":    "example.com",
+		"timeout": 50,
+		"port":    3,
+		"new":     true,
+		"list":    []any{1, 2, 3},
+		"db":      map[string]any{"user": "a", "pass": "y"},
+		"cache":   "off",
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "stylish",
+			want: `{
+  > cache: {
+        ttl: 10
+    } -> off
+    db: {
+      > pass: x -> y
+      < user: a -> b
+    }
+  > host: hexlet.io -> example.com
+  > list: [1,2] -> [1,2,3]
+  = new: (absent) -> true
+  = old: gone -> (absent)
+  ! port: 1 -> ours: 2, theirs: 3
+  < timeout: 50 -> 20
+}`,
+		},
+		{
+			format: "plain",
+			want: `Property 'cache' was updated in theirs. From [complex value] to 'off'
+Property 'db.pass' was updated in theirs. From 'x' to 'y'
+Property 'db.user' was updated in ours. From 'a' to 'b'
+Property 'host' was updated in theirs. From 'hexlet.io' to 'example.com'
+Property 'list' was updated in theirs. From [complex value] to [complex value]
+Property 'new' was added in both with value: true
+Property 'old' was removed in both
+Property 'port' has conflicting changes. Base: 1, ours: 2, theirs: 3
+Property 'timeout' was updated in ours. From 50 to 20`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := require.New(t)
+
+			nodes, err := DiffThreeWay(base, ours, theirs)
+			r.NoError(err)
+			got, err := FormatThreeWay(nodes, tt.format)
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestThreeWayDiffConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   map[string]any
+		ours   map[string]any
+		theirs map[string]any
+		want   ThreeWayStatus
+	}{
+		{
+			name:   "unchanged",
+			base:   map[string]any{"k": 1},
+			ours:   map[string]any{"k": 1},
+			theirs: map[string]any{"k": 1},
+			want:   ThreeWayUnchanged,
+		},
+		{
+			name:   "added differently",
+			base:   map[string]any{},
+			ours:   map[string]any{"k": 1},
+			theirs: map[string]any{"k": 2},
+			want:   ThreeWayConflict,
+		},
+		{
+			name:   "removed in ours, changed in theirs",
+			base:   map[string]any{"k": map[string]any{"a": 1}},
+			ours:   map[string]any{},
+			theirs: map[string]any{"k": map[string]any{"a": 2}},
+			want:   ThreeWayConflict,
+		},
+		{
+			name:   "same array change",
+			base:   map[string]any{"k": []any{1}},
+			ours:   map[string]any{"k": []any{1, 2}},
+			theirs: map[string]any{"k": []any{1, 2}},
+			want:   ThreeWayBoth,
+		},
+		{
+			name:   "objects changed on both sides",
+			base:   map[string]any{"k": map[string]any{"a": 1, "b": 1}},
+			ours:   map[string]any{"k": map[string]any{"a": 2, "b": 1}},
+			theirs: map[string]any{"k": map[string]any{"a": 1, "b": 2}},
+			want:   ThreeWayNested,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			nodes, err := DiffThreeWay(tt.base, tt.ours, tt.theirs)
+			r.NoError(err)
+			r.Len(nodes, 1)
+			r.Equal(tt.want, nodes[0].Status)
+		})
+	}
+}
+
+func TestThreeWayDiffJSON(t *testing.T) {
+	r := require.New(t)
+
+	nodes, err := DiffThreeWay(
+		map[string]any{"k": 1, "gone": "x"},
+		map[string]any{"k": 2},
+		map[string]any{"k": 1, "gone": "x"},
+	)
+	r.NoError(err)
+
+	got, err := formatters.FormatThreeWayJSON(nodes)
+	r.NoError(err)
+	r.JSONEq(`{
+		"gone": {"status": "ours", "base": "x", "theirs": "x"},
+		"k": {"status": "ours", "base": 1, "ours": 2, "theirs": 1}
+	}`, got)
+
+	_, err = FormatThreeWay(nodes, "jsonpatch")
+	r.ErrorContains(err, "does not support three-way diffs")
+}
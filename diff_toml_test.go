@@ -0,0 +1,130 @@
+package code
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDiffTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []models.FileData
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "tables and arrays",
+			files: []models.FileData{
+				{Content: []byte("[package]\nname = \"app\"\nversion = \"0.1.0\"\n\n[dependencies]\nserde = \"1.0\"\n"), Format: ".toml"},
+				{Content: []byte("[package]\nname = \"app\"\nversion = \"0.2.0\"\nauthors = [\"me\"]\n\n[dependencies]\nserde = \"1.0\"\n"), Format: ".toml"},
+			},
+			format: "plain",
+			want: `Property 'package.authors' was added with value: [complex value]
+Property 'package.version' was updated. From '0.1.0' to '0.2.0'`,
+		},
+		{
+			name: "toml vs yaml",
+			files: []models.FileData{
+				{Content: []byte("[server]\nhost = \"localhost\"\n"), Format: ".toml"},
+				{Content: []byte("server:\n  host: example.com\n"), Format: ".yaml"},
+			},
+			format: "stylish",
+			want: `{
+    server: {
+      - host: localhost
+      + host: example.com
+    }
+}`,
+		},
+		{
+			name: "datetimes in stylish",
+			files: []models.FileData{
+				{Content: []byte("released = 1979-05-27T07:32:00Z\nday = 1979-05-27\n"), Format: ".toml"},
+				{Content: []byte("released = 1979-05-28T07:32:00Z\nday = 1979-05-27\n"), Format: ".toml"},
+			},
+			format: "stylish",
+			want: `{
+    day: 1979-05-27
+  - released: 1979-05-27T07:32:00Z
+  + released: 1979-05-28T07:32:00Z
+}`,
+		},
+		{
+			name: "datetimes in plain are not quoted",
+			files: []models.FileData{
+				{Content: []byte("at = \"07:32:00\"\n"), Format: ".toml"},
+				{Content: []byte("at = 07:32:00\n"), Format: ".toml"},
+			},
+			format: "plain",
+			want:   "Property 'at' was updated. From '07:32:00' to 07:32:00",
+		},
+		{
+			name: "datetimes toml vs yaml",
+			files: []models.FileData{
+				{Content: []byte("d = 2020-01-01T00:00:00Z\nday = 2020-01-01\nlocal = 2020-01-01T10:30:00\nshifted = 2020-01-01T10:30:00+02:00\n"), Format: ".toml"},
+				{Content: []byte("d: 2020-01-01T00:00:00Z\nday: 2020-01-01\nlocal: 2020-01-01 10:30:00\nshifted: 2020-01-01T10:30:00+03:00\n"), Format: ".yaml"},
+			},
+			format: "stylish",
+			want: `{
+    d: 2020-01-01T00:00:00Z
+    day: 2020-01-01
+    local: 2020-01-01T10:30:00
+  - shifted: 2020-01-01T10:30:00+02:00
+  + shifted: 2020-01-01T10:30:00+03:00
+}`,
+		},
+		{
+			name: "invalid toml",
+			files: []models.FileData{
+				{Content: []byte("key = \n"), Format: ".toml"},
+				{Content: []byte(""), Format: ".toml"},
+			},
+			format:  "stylish",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := genDiffFromData(tt.files, tt.format)
+
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestGenDiffTOMLDateTimeJSONFormat(t *testing.T) {
+	files := []models.FileData{
+		{Content: []byte("at = 1979-05-27T07:32:00\n"), Format: ".toml"},
+		{Content: []byte("at = 1979-05-27T07:32:00Z\n"), Format: ".toml"},
+	}
+	want := `{
+  "at": {
+    "type": "changed",
+    "oldValue": {"kind": "local-datetime", "value": "1979-05-27T07:32:00"},
+    "newValue": {"kind": "offset-datetime", "value": "1979-05-27T07:32:00Z"}
+  }
+}`
+
+	r := require.New(t)
+
+	got, err := genDiffFromData(files, "json")
+	r.NoError(err)
+
+	var gotJSON, wantJSON any
+	r.NoError(json.Unmarshal([]byte(got), &gotJSON))
+	r.NoError(json.Unmarshal([]byte(want), &wantJSON))
+	r.Equal(wantJSON, gotJSON)
+}
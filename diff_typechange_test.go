@@ -0,0 +1,142 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTypeChange(t *testing.T) {
+	old := map[string]any{
+		"db":      "postgres://localhost",
+		"hosts":   map[string]any{"web": "10.0.0.1"},
+		"servers": []any{"a", map[string]any{"port": 80.0}},
+		"port":    "80",
+	}
+	new := map[string]any{
+		"db":      map[string]any{"host": "localhost"},
+		"hosts":   []any{"10.0.0.1"},
+		"servers": []any{"a", nil},
+		"port":    80.0,
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "plain",
+			want: `Property 'db' changed type from string to object
+Property 'hosts' changed type from object to array
+Property 'port' was updated. From '80' to 80
+Property 'servers[1]' changed type from object to null`,
+		},
+		{
+			format: "json",
+			want: `{
+  "db": {
+    "newKind": "object",
+    "newValue": {
+      "host": "localhost"
+    },
+    "oldKind": "string",
+    "oldValue": "postgres://localhost",
+    "type": "typeChanged"
+  },
+  "hosts": {
+    "newKind": "array",
+    "newValue": [
+      "10.0.0.1"
+    ],
+    "oldKind": "object",
+    "oldValue": {
+      "web": "10.0.0.1"
+    },
+    "type": "typeChanged"
+  },
+  "port": {
+    "newValue": 80,
+    "oldValue": "80",
+    "type": "changed"
+  },
+  "servers": {
+    "children": [
+      {
+        "key": "0",
+        "newIndex": 0,
+        "type": "unchanged",
+        "value": "a"
+      },
+      {
+        "key": "1",
+        "newIndex": 1,
+        "newKind": "null",
+        "newValue": null,
+        "oldKind": "object",
+        "oldValue": {
+          "port": 80
+        },
+        "type": "typeChanged"
+      }
+    ],
+    "type": "array"
+  }
+}`,
+		},
+		{
+			format: "stylish",
+			want: `{
+  - db: postgres://localhost
+  + db: {
+        host: localhost
+    }
+  - hosts: {
+        web: 10.0.0.1
+    }
+  + hosts: ["10.0.0.1"]
+  - port: 80
+  + port: 80
+    servers: [
+        a
+      - {
+            port: 80
+        }
+      + null
+    ]
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			r := require.New(t)
+
+			nodes, err := Diff(old, new)
+			r.NoError(err)
+			got, err := Format(nodes, tt.format)
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestFailOnTypeChange(t *testing.T) {
+	r := require.New(t)
+
+	old := map[string]any{"db": "postgres", "list": []any{map[string]any{"a": 1.0}}, "port": "80"}
+	new := map[string]any{"db": map[string]any{"host": "localhost"}, "list": []any{"a"}, "port": 80.0}
+
+	nodes, err := Diff(old, new)
+	r.NoError(err)
+	r.Equal([]string{"db", "list[0]"}, TypeChanges(nodes))
+
+	_, err = Diff(old, new, WithFailOnTypeChange())
+	r.ErrorIs(err, ErrTypeChanged)
+	r.EqualError(err, "value changed type: db, list[0]")
+
+	_, err = Diff(map[string]any{"port": "80"}, map[string]any{"port": 80.0}, WithFailOnTypeChange())
+	r.NoError(err)
+
+	_, err = GenDiff("testdata/fixture/nested.json", "testdata/fixture/nested2.json", "plain", WithFailOnTypeChange())
+	r.ErrorIs(err, ErrTypeChanged)
+}
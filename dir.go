@@ -0,0 +1,190 @@
+package code
+
+import (
+	"bytes"
+	"code/internal/models"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// DirSummary counts the files of a directory comparison by outcome.
+type DirSummary struct {
+	Changed   int
+	Added     int
+	Removed   int
+	Unchanged int
+}
+
+// String returns the summary line, e.g.
+// "2 files changed, 1 added, 0 removed, 5 unchanged".
+func (s DirSummary) String() string {
+	noun := "files"
+	if s.Changed == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("%d %s changed, %d added, %d removed, %d unchanged", s.Changed, noun, s.Added, s.Removed, s.Unchanged)
+}
+
+// IsDir reports whether path names a directory.
+func IsDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// DiffDirs walks two directory trees, pairs their files by relative path
+// and compares every pair, several at a time. Configuration files, whose
+// format is recognised from their name, are decoded and diffed; other files
+// are compared byte by byte. Files present in one tree only are reported as
+// added or removed without being read.
+//
+// The result has one FileDiff per file, keyed by its slash-separated
+// relative path and sorted by it. Render it with FormatDir.
+func DiffDirs(ctx context.Context, dir1, dir2 string, opts ...Option) ([]FileDiff, DirSummary, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, DirSummary{}, err
+	}
+
+	oldFiles, err := dirFiles(dir1)
+	if err != nil {
+		return nil, DirSummary{}, err
+	}
+	newFiles, err := dirFiles(dir2)
+	if err != nil {
+		return nil, DirSummary{}, err
+	}
+
+	rels := make([]string, 0, len(oldFiles)+len(newFiles))
+	for rel := range oldFiles {
+		rels = append(rels, rel)
+	}
+	for rel := range newFiles {
+		if _, ok := oldFiles[rel]; !ok {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+
+	files := make([]models.FileDiff, len(rels))
+	errs := make([]error, len(rels))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, rel := range rels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if errs[i] = ctx.Err(); errs[i] == nil {
+				files[i], errs[i] = diffDirEntry(rel, oldFiles[rel], newFiles[rel], options)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var summary DirSummary
+	var nodes []models.DiffNode
+	for i, file := range files {
+		if errs[i] != nil {
+			return nil, DirSummary{}, errs[i]
+		}
+		switch file.Status {
+		case models.FileAdded:
+			summary.Added++
+		case models.FileRemoved:
+			summary.Removed++
+		case models.FileChanged:
+			summary.Changed++
+		default:
+			summary.Unchanged++
+		}
+		nodes = append(nodes, file.Nodes...)
+	}
+	if err := checkTypeChanges(nodes, options); err != nil {
+		return nil, DirSummary{}, err
+	}
+	return files, summary, nil
+}
+
+// dirFiles returns the regular files under dir, keyed by their
+// slash-separated path relative to dir.
+func dirFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = path
+		return nil
+	})
+	return files, err
+}
+
+// diffDirEntry compares the files found under one relative path; an empty
+// path means the file is missing from that tree.
+func diffDirEntry(rel, oldPath, newPath string, opts Options) (models.FileDiff, error) {
+	switch {
+	case newPath == "":
+		return models.FileDiff{Path: rel, Status: models.FileRemoved}, nil
+	case oldPath == "":
+		return models.FileDiff{Path: rel, Status: models.FileAdded}, nil
+	}
+
+	if _, err := detectFormat(rel); err != nil {
+		return diffOtherFiles(rel, oldPath, newPath)
+	}
+	filesData, err := readFiles([]string{oldPath, newPath}, Options{})
+	if err != nil {
+		return models.FileDiff{}, err
+	}
+	docs, err := decodeFiles(filesData, opts)
+	if err != nil {
+		return models.FileDiff{}, err
+	}
+
+	file := models.FileDiff{Path: rel, Status: models.FileUnchanged, Nodes: buildDiffTree(docs[0], docs[1], nil, opts)}
+	if HasChanges(file.Nodes) {
+		file.Status = models.FileChanged
+	}
+	return file, nil
+}
+
+// diffOtherFiles compares two files that are not configuration files by
+// content.
+func diffOtherFiles(rel, oldPath, newPath string) (models.FileDiff, error) {
+	old, err := os.ReadFile(oldPath)
+	if err != nil {
+		return models.FileDiff{}, err
+	}
+	new, err := os.ReadFile(newPath)
+	if err != nil {
+		return models.FileDiff{}, err
+	}
+	file := models.FileDiff{Path: rel, Status: models.FileUnchanged}
+	if !bytes.Equal(old, new) {
+		file.Status = models.FileChanged
+	}
+	return file, nil
+}
+
+// HasFileChanges reports whether a directory comparison found any file
+// added, removed or changed.
+func HasFileChanges(files []FileDiff) bool {
+	for _, file := range files {
+		if file.Status != FileUnchanged {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package code
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseDotenv decodes a dotenv document into a flat map of strings.
+// It accepts "KEY=VALUE" lines with an optional "export " prefix, full-line
+// comments and " #" comments after values. Single-quoted values are
+// literal, double-quoted values expand \n, \t, \" and \; both kinds of
+// quotes may span several lines. Any other text after a closing quote is an
+// error.
+func parseDotenv(data []byte) (map[string]any, error) {
+	result := make(map[string]any)
+	src := strings.ReplaceAll(string(data), "\r\n", "\n")
+	line := 1
+	for len(src) > 0 {
+		var current string
+		current, src = cutLine(src)
+		start := line
+		line++
+
+		trimmed := strings.TrimSpace(current)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		trimmed = strings.TrimPrefix(trimmed, "export ")
+
+		eq := strings.IndexByte(trimmed, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("dotenv: line %d: expected KEY=VALUE", start)
+		}
+		key := strings.TrimSpace(trimmed[:eq])
+		value := strings.TrimLeft(trimmed[eq+1:], " \t")
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+			result[key] = strings.TrimSpace(value)
+			continue
+		}
+
+		quote := value[0]
+		body := value[1:]
+		end := closingQuote(body, quote)
+		for end < 0 {
+			if src == "" {
+				return nil, fmt.Errorf("dotenv: line %d: unterminated quoted value for %s", start, key)
+			}
+			current, src = cutLine(src)
+			line++
+			body += "\n" + current
+			end = closingQuote(body, quote)
+		}
+
+		if rest := strings.TrimLeft(body[end+1:], " \t"); rest != "" && (rest[0] != '#' || rest == body[end+1:]) {
+			return nil, fmt.Errorf("dotenv: line %d: unexpected text after quoted value for %s", line-1, key)
+		}
+		body = body[:end]
+		if quote == '"' {
+			body = expandDotenvEscapes(body)
+		}
+		result[key] = body
+	}
+	return result, nil
+}
+
+func cutLine(s string) (string, string) {
+	line, rest, _ := strings.Cut(s, "\n")
+	return line, rest
+}
+
+// closingQuote returns the index of the first unescaped quote in s, or -1.
+// Backslash escapes only apply inside double quotes.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && quote == '"' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func expandDotenvEscapes(s string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(s)
+}
+
+// serializeDotenv writes one "KEY=VALUE" line per entry, sorted by key.
+// Values that would not survive unquoted are double-quoted with escapes.
+func serializeDotenv(doc map[string]any) ([]byte, error) {
+	var sb strings.Builder
+	for _, key := range sortedKeys(doc) {
+		s, err := scalarString(doc[key])
+		if err != nil {
+			return nil, fmt.Errorf("dotenv: %s: %w", key, err)
+		}
+		if s != strings.TrimSpace(s) || strings.ContainsAny(s, "#\"'\\\n\t") {
+			s = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", key, s)
+	}
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,61 @@
+package code
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// parsePathPattern turns an ignore or only pattern into path segments. Patterns
+// starting with "/" are JSON Pointers ("/metadata/resourceVersion"), with
+// "~1" and "~0" standing for "/" and "~"; anything else is a dotted path
+// ("metadata.resourceVersion", "spec.containers[*].image"). Segments may be
+// "*" to match any one key or index, or "**" to match any number of them.
+func parsePathPattern(pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty path pattern")
+	}
+	if !strings.HasPrefix(pattern, "/") {
+		return splitPath(pattern), nil
+	}
+
+	segments := strings.Split(pattern[1:], "/")
+	for i, segment := range segments {
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+	}
+	return segments, nil
+}
+
+func parsePathPatterns(patterns []string) ([][]string, error) {
+	parsed := make([][]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		segments, err := parsePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, segments)
+	}
+	return parsed, nil
+}
+
+// ReadIgnoreFile reads ignore patterns from a file, one per line. Blank
+// lines and lines starting with "#" are skipped.
+func ReadIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
@@ -0,0 +1,128 @@
+package code
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// parseINI decodes an INI document into a map. Keys that appear before
+// the first section header are top-level entries, every [section] becomes a
+// nested map. Both "key = value" and "key: value" are accepted, lines
+// starting with ";" or "#" are comments, and values stay strings with one
+// pair of surrounding quotes removed. A key without a value maps to "".
+func parseINI(data []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	current := root
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("ini: line %d: unterminated section header", lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			section, ok := root[name].(map[string]any)
+			if !ok {
+				section = make(map[string]any)
+				root[name] = section
+			}
+			current = section
+			continue
+		}
+
+		key, value := line, ""
+		if i := strings.IndexAny(line, "=:"); i >= 0 {
+			key, value = strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		}
+		if key == "" {
+			return nil, fmt.Errorf("ini: line %d: missing key", lineNo)
+		}
+		current[key] = unquote(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// looksLikeINI reports whether every significant line is a section header
+// or an assignment. parseINI also accepts bare keys, which would make any
+// text pass as INI when the format has to be guessed.
+func looksLikeINI(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' || line[0] == '[' {
+			continue
+		}
+		if !strings.ContainsAny(line, "=:") {
+			return false
+		}
+	}
+	return true
+}
+
+// unquote strips one pair of matching single or double quotes.
+func unquote(value string) string {
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// serializeINI writes top-level scalars first and then one section per
+// nested object, keys sorted. Deeper nesting and arrays have no INI form.
+func serializeINI(doc map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	var sections []string
+	for _, key := range sortedKeys(doc) {
+		if _, ok := doc[key].(map[string]any); ok {
+			sections = append(sections, key)
+			continue
+		}
+		if err := writeINIEntry(&buf, "", key, doc[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range sections {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "[%s]\n", name)
+		section := doc[name].(map[string]any)
+		for _, key := range sortedKeys(section) {
+			if err := writeINIEntry(&buf, name, key, section[key]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeINIEntry writes one "key = value" line of the given section, empty
+// for top-level entries. Keys are written verbatim, dots included.
+func writeINIEntry(buf *bytes.Buffer, section, key string, value any) error {
+	s, err := scalarString(value)
+	if err != nil {
+		if section != "" {
+			return fmt.Errorf("ini: [%s] %s: %w", section, key, err)
+		}
+		return fmt.Errorf("ini: %s: %w", key, err)
+	}
+	if s != strings.TrimSpace(s) || strings.ContainsAny(s, ";#") {
+		s = `"` + s + `"`
+	}
+	fmt.Fprintf(buf, "%s = %s\n", key, s)
+	return nil
+}
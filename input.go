@@ -0,0 +1,113 @@
+package code
+
+import (
+	"code/internal/models"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinPath is the path that stands for standard input.
+const stdinPath = "-"
+
+// stdin is read when a path is "-". Tests replace it.
+var stdin io.Reader = os.Stdin
+
+// readFile loads the file at path, or standard input when path is "-", and
+// resolves its format: the explicit one when format is not empty, otherwise
+// the first one the content parses as, trying the format implied by the
+// path before the others.
+func readFile(path, format string) (models.FileData, error) {
+	var data []byte
+	var err error
+	if path == stdinPath {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return models.FileData{}, err
+	}
+
+	if format == "" {
+		var preferred string
+		if path != stdinPath {
+			preferred, _ = detectFormat(path)
+		}
+		if format, err = sniffFormat(path, data, preferred); err != nil {
+			return models.FileData{}, err
+		}
+	}
+
+	return models.FileData{Path: path, Content: data, Format: format}, nil
+}
+
+// readFiles loads every path with readFile, taking the format override of
+// each input from options. At most one path may be "-".
+func readFiles(paths []string, options Options) ([]models.FileData, error) {
+	filesData := make([]models.FileData, 0, len(paths))
+	fromStdin := 0
+	for i, path := range paths {
+		if path == stdinPath {
+			if fromStdin++; fromStdin > 1 {
+				return nil, fmt.Errorf("only one file can be read from stdin")
+			}
+		}
+		fd, err := readFile(path, options.inputFormat(i))
+		if err != nil {
+			return nil, err
+		}
+		filesData = append(filesData, fd)
+	}
+	return filesData, nil
+}
+
+// sniffOrder lists the formats tried, in order, on content whose format is
+// not known. Stricter formats come first: JSON is also valid YAML, and
+// almost any line is an INI key. Registered custom formats are never
+// guessed.
+var sniffOrder = []string{inputJSON, inputTOML, inputYAML, inputINI}
+
+// sniffFormat returns the first format that decodes data into an object:
+// preferred, usually implied by the file extension, when not empty and then
+// those of sniffOrder. Extensions can mislead, e.g. YAML saved as .json, so
+// a preferred format that fails is not final. The error lists every format
+// tried and why it was rejected.
+func sniffFormat(path string, data []byte, preferred string) (string, error) {
+	name := path
+	if path == stdinPath {
+		name = "stdin"
+	}
+	formats := sniffOrder
+	if preferred != "" {
+		formats = []string{preferred}
+		for _, format := range sniffOrder {
+			if format != preferred {
+				formats = append(formats, format)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "cannot detect the format of %s, tried:", name)
+	for _, format := range formats {
+		_, err := parseFile(data, format)
+		if err == nil && format == inputINI && format != preferred && !looksLikeINI(data) {
+			err = errors.New("not made of sections and key = value lines")
+		}
+		if err == nil {
+			return format, nil
+		}
+		fmt.Fprintf(&sb, "\n  %s: %v", format, err)
+	}
+	return "", errors.New(sb.String())
+}
+
+// normalizeInputFormat turns a user supplied format name such as "YAML",
+// ".yml" or "env" into the name of its registered parser.
+func normalizeInputFormat(name string) (string, error) {
+	format, _, err := lookupParser(name)
+	return format, err
+}
@@ -0,0 +1,66 @@
+package formatters
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FormatDir renders a directory comparison in the named format. Only
+// "stylish", "plain" and "json" support directory comparisons; patch
+// formats address a single document and cannot span several files.
+func FormatDir(files []models.FileDiff, format string) (string, error) {
+	switch format {
+	case formatStylish:
+		return formatDirText(files, FormatStylish), nil
+	case formatPlain:
+		return formatDirText(files, FormatPlain), nil
+	case formatJson:
+		return FormatDirJSON(files)
+	}
+	return "", fmt.Errorf("format %s does not support directory comparisons (available: %s, %s, %s)",
+		format, formatStylish, formatPlain, formatJson)
+}
+
+// formatDirText writes one line per added, removed or changed file:
+//   - "File 'path' was added"
+//   - "File 'path' was removed"
+//   - "File 'path' was changed", followed for configuration files by a colon
+//     and their diff in the given format
+//
+// Unchanged files are not shown.
+func formatDirText(files []models.FileDiff, format func([]models.DiffNode) string) string {
+	var blocks []string
+	for _, file := range files {
+		if file.Status == models.FileUnchanged {
+			continue
+		}
+		header := fmt.Sprintf("File '%s' was %s", file.Path, file.Status)
+		if file.Nodes == nil {
+			blocks = append(blocks, header)
+			continue
+		}
+		blocks = append(blocks, header+":\n"+format(file.Nodes))
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// FormatDirJSON formats a directory comparison as a JSON array with one
+// {"path", "status"} object per file, in path order. Changed configuration
+// files also carry their diff as "diff", in the json format.
+func FormatDirJSON(files []models.FileDiff) (string, error) {
+	result := make([]any, 0, len(files))
+	for _, file := range files {
+		entry := map[string]any{"path": file.Path, "status": file.Status}
+		if file.Status == models.FileChanged && file.Nodes != nil {
+			entry["diff"] = nodesToMap(file.Nodes)
+		}
+		result = append(result, entry)
+	}
+	bytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
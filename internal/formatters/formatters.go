@@ -3,33 +3,105 @@ package formatters
 import (
 	"code/internal/models"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 )
 
-// Supported output formats
+// Built-in output formats
 const (
 	formatStylish = "stylish"
 	formatPlain   = "plain"
 	formatJson    = "json"
+	formatPatch   = "jsonpatch"
+	formatMerge   = "mergepatch"
 )
 
+// Formatter renders a diff tree as text.
+type Formatter interface {
+	Format(nodes []models.DiffNode) (string, error)
+}
+
+// FormatterFunc adapts an ordinary function to the Formatter interface.
+type FormatterFunc func(nodes []models.DiffNode) (string, error)
+
+// Format calls f(nodes).
+func (f FormatterFunc) Format(nodes []models.DiffNode) (string, error) {
+	return f(nodes)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Formatter)
+)
+
+func init() {
+	mustRegister(formatStylish, FormatterFunc(func(nodes []models.DiffNode) (string, error) {
+		return FormatStylish(nodes), nil
+	}))
+	mustRegister(formatPlain, FormatterFunc(func(nodes []models.DiffNode) (string, error) {
+		return FormatPlain(nodes), nil
+	}))
+	mustRegister(formatJson, FormatterFunc(FormatJSON))
+	mustRegister(formatPatch, FormatterFunc(FormatJSONPatch))
+	mustRegister(formatMerge, FormatterFunc(FormatMergePatch))
+}
+
+// Register makes a formatter available under name. It fails when the name
+// is empty, the formatter is nil or the name is already taken.
+func Register(name string, f Formatter) error {
+	if name == "" {
+		return fmt.Errorf("formatter name is empty")
+	}
+	if f == nil {
+		return fmt.Errorf("formatter %s is nil", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		return fmt.Errorf("formatter %s is already registered", name)
+	}
+	registry[name] = f
+	return nil
+}
+
+func mustRegister(name string, f Formatter) {
+	if err := Register(name, f); err != nil {
+		panic(err)
+	}
+}
+
+// Names returns the names of all registered formatters, sorted.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Format formats a diff tree according to the specified format.
-// It acts as a dispatcher, selecting the appropriate formatter based on the format parameter.
+// It acts as a dispatcher, looking up the formatter registered under the
+// format name.
 //
-// Supported formats:
+// Built-in formats:
 //   - "stylish": Hierarchical format with indentation and markers (default)
 //   - "plain": Flat text format with property paths
 //   - "json": json format
+//   - "jsonpatch": RFC 6902 JSON Patch
+//   - "mergepatch": RFC 7386 JSON Merge Patch
 //
-// Returns an error if an unknown format is specified.
+// Returns an error listing the registered formats if the format is unknown.
 func Format(nodes []models.DiffNode, format string) (string, error) {
-	switch format {
-	case formatStylish:
-		return FormatStylish(nodes), nil
-	case formatPlain:
-		return FormatPlain(nodes), nil
-	case formatJson:
-		return FormatJSON(nodes)
-	default:
-		return "", fmt.Errorf("unknown format: %s", format)
+	registryMu.RLock()
+	f, ok := registry[format]
+	registryMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown format: %s (available: %s)", format, strings.Join(Names(), ", "))
 	}
+	return f.Format(nodes)
 }
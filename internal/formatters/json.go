@@ -3,6 +3,7 @@ package formatters
 import (
 	"code/internal/models"
 	"encoding/json"
+	"strconv"
 )
 
 func FormatJSON(nodes []models.DiffNode) (string, error) {
@@ -22,6 +23,32 @@ func nodesToMap(nodes []models.DiffNode) map[string]any {
 	return result
 }
 
+// nodesToList keeps array element diffs in order, which a JSON object
+// keyed by index would not. Each entry carries its index or identity as
+// "key". Removed elements are keyed by their index in the old list and the
+// others by their index in the new one, so entries matched by position also
+// tell the index space apart: "oldIndex" for removed elements, "newIndex"
+// for the rest.
+func nodesToList(nodes []models.DiffNode) []any {
+	result := make([]any, 0, len(nodes))
+	for _, node := range nodes {
+		value, ok := nodeToValue(node).(map[string]any)
+		if !ok {
+			continue
+		}
+		value["key"] = node.Key
+		if index, err := strconv.Atoi(node.Key); err == nil {
+			if node.Type == models.NodeTypeRemoved {
+				value["oldIndex"] = index
+			} else {
+				value["newIndex"] = index
+			}
+		}
+		result = append(result, value)
+	}
+	return result
+}
+
 func nodeToValue(node models.DiffNode) any {
 	switch node.Type {
 	case models.NodeTypeAdded:
@@ -45,11 +72,30 @@ func nodeToValue(node models.DiffNode) any {
 			"type":  "unchanged",
 			"value": node.OldValue,
 		}
+	case models.NodeTypeTypeChanged:
+		return map[string]any{
+			"type":     "typeChanged",
+			"oldKind":  node.OldKind,
+			"newKind":  node.NewKind,
+			"oldValue": node.OldValue,
+			"newValue": node.NewValue,
+		}
+	case models.NodeTypeEquivalent:
+		return map[string]any{
+			"type":     "equivalent",
+			"oldValue": node.OldValue,
+			"newValue": node.NewValue,
+		}
 	case models.NodeTypeNested:
 		return map[string]any{
 			"type":     "nested",
 			"children": nodesToMap(node.Children),
 		}
+	case models.NodeTypeArray:
+		return map[string]any{
+			"type":     "array",
+			"children": nodesToList(node.Children),
+		}
 	}
 	return nil
 }
@@ -0,0 +1,191 @@
+package formatters
+
+import (
+	"bytes"
+	"code/internal/models"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// patchOp is one RFC 6902 operation.
+type patchOp struct {
+	Op    string
+	From  string
+	Path  string
+	Value any
+}
+
+// MarshalJSON writes the members in the order of the RFC examples and only
+// includes "value" for operations that carry one, even when it is null.
+// Dates and times in values are written as plain strings, see patchValueOf.
+func (p patchOp) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"op":`)
+	writeJSON(&buf, p.Op)
+	if p.Op == "move" {
+		buf.WriteString(`,"from":`)
+		writeJSON(&buf, p.From)
+	}
+	buf.WriteString(`,"path":`)
+	writeJSON(&buf, p.Path)
+	if p.Op == "add" || p.Op == "replace" {
+		buf.WriteString(`,"value":`)
+		if err := writeJSON(&buf, patchValueOf(p.Value)); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func writeJSON(buf *bytes.Buffer, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// FormatJSONPatch formats a diff tree as an RFC 6902 JSON Patch: an array of
+// operations that turns the first file into the second one.
+//   - Removed keys become "remove", added keys "add", changed values "replace"
+//   - Values equivalent after coercion are left as they are
+//   - Dates and times are written as their RFC 3339 text
+//   - A key removed and another added with an equal value in the same object
+//     become a single "move"
+//   - Nested objects are descended into, keys are escaped as JSON Pointer
+//     tokens ("~" as "~0", "/" as "~1")
+//   - Array elements are addressed by their index at the time the operation
+//     applies; arrays matched by an identity field are replaced as a whole
+func FormatJSONPatch(nodes []models.DiffNode) (string, error) {
+	ops := patchObject(nodes, "", []patchOp{})
+	out, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func patchObject(nodes []models.DiffNode, parent string, ops []patchOp) []patchOp {
+	moves := detectMoves(nodes)
+
+	for _, node := range nodes {
+		path := parent + "/" + escapePointer(node.Key)
+
+		switch node.Type {
+		case models.NodeTypeRemoved:
+			if _, moved := moves[node.Key]; !moved {
+				ops = append(ops, patchOp{Op: "remove", Path: path})
+			}
+		case models.NodeTypeAdded:
+			if from, moved := moves[node.Key]; moved {
+				ops = append(ops, patchOp{Op: "move", From: parent + "/" + escapePointer(from), Path: path})
+			} else {
+				ops = append(ops, patchOp{Op: "add", Path: path, Value: node.NewValue})
+			}
+		default:
+			ops = patchValue(node, path, ops)
+		}
+	}
+	return ops
+}
+
+// patchValue appends the operations for a node present on both sides.
+func patchValue(node models.DiffNode, path string, ops []patchOp) []patchOp {
+	switch node.Type {
+	case models.NodeTypeChanged, models.NodeTypeTypeChanged:
+		ops = append(ops, patchOp{Op: "replace", Path: path, Value: node.NewValue})
+	case models.NodeTypeNested:
+		ops = patchObject(node.Children, path, ops)
+	case models.NodeTypeArray:
+		ops = patchArray(node, path, ops)
+	}
+	return ops
+}
+
+// detectMoves pairs removed and added siblings holding equal values. The
+// result maps each paired added key, and each paired removed key, to the
+// key on the other side.
+func detectMoves(nodes []models.DiffNode) map[string]string {
+	moves := make(map[string]string)
+	var removed []models.DiffNode
+	for _, node := range nodes {
+		if node.Type == models.NodeTypeRemoved {
+			removed = append(removed, node)
+		}
+	}
+
+	for _, node := range nodes {
+		if node.Type != models.NodeTypeAdded {
+			continue
+		}
+		for i, r := range removed {
+			if reflect.DeepEqual(r.OldValue, node.NewValue) {
+				moves[node.Key] = r.Key
+				moves[r.Key] = node.Key
+				removed = append(removed[:i], removed[i+1:]...)
+				break
+			}
+		}
+	}
+	return moves
+}
+
+// patchArray walks the element diffs in list order, keeping track of the
+// position each element has once the preceding operations are applied.
+func patchArray(node models.DiffNode, path string, ops []patchOp) []patchOp {
+	if !hasIndexKeys(node.Children) {
+		if hasChanges(node.Children) {
+			ops = append(ops, patchOp{Op: "replace", Path: path, Value: node.NewValue})
+		}
+		return ops
+	}
+
+	pos := 0
+	for _, child := range node.Children {
+		elemPath := path + "/" + strconv.Itoa(pos)
+		switch child.Type {
+		case models.NodeTypeRemoved:
+			ops = append(ops, patchOp{Op: "remove", Path: elemPath})
+			continue
+		case models.NodeTypeAdded:
+			ops = append(ops, patchOp{Op: "add", Path: elemPath, Value: child.NewValue})
+		default:
+			ops = patchValue(child, elemPath, ops)
+		}
+		pos++
+	}
+	return ops
+}
+
+func hasIndexKeys(nodes []models.DiffNode) bool {
+	for _, node := range nodes {
+		if _, err := strconv.Atoi(node.Key); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func hasChanges(nodes []models.DiffNode) bool {
+	for _, node := range nodes {
+		switch node.Type {
+		case models.NodeTypeUnchanged, models.NodeTypeEquivalent:
+		case models.NodeTypeNested, models.NodeTypeArray:
+			if hasChanges(node.Children) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// escapePointer escapes a key as a JSON Pointer reference token (RFC 6901).
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
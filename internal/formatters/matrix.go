@@ -0,0 +1,136 @@
+package formatters
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const formatMarkdown = "markdown"
+
+// FormatMatrix renders a comparison matrix in the named format: "stylish",
+// "markdown" or "json".
+func FormatMatrix(matrix models.Matrix, format string) (string, error) {
+	switch format {
+	case formatStylish:
+		return FormatMatrixStylish(matrix), nil
+	case formatMarkdown:
+		return FormatMatrixMarkdown(matrix), nil
+	case formatJson:
+		return FormatMatrixJSON(matrix)
+	}
+	return "", fmt.Errorf("format %s does not support comparison matrices (available: %s, %s, %s)",
+		format, formatStylish, formatMarkdown, formatJson)
+}
+
+// FormatMatrixStylish formats a comparison matrix as aligned text columns:
+// the key path followed by its value in each file. Rows where the files
+// disagree are prefixed with "! ", the others with "  ". Values are written
+// like in the stylish format and missing ones as (absent).
+func FormatMatrixStylish(matrix models.Matrix) string {
+	table := make([][]string, 0, len(matrix.Rows)+1)
+	table = append(table, append([]string{"key"}, matrix.Files...))
+	for _, row := range matrix.Rows {
+		cells := make([]string, 0, len(row.Values)+1)
+		cells = append(cells, row.Path)
+		for _, value := range row.Values {
+			cells = append(cells, formatMatrixValue(value))
+		}
+		table = append(table, cells)
+	}
+
+	widths := make([]int, len(table[0]))
+	for _, cells := range table {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := make([]string, 0, len(table))
+	for i, cells := range table {
+		marker := "  "
+		if i > 0 && matrix.Rows[i-1].Diverges {
+			marker = "! "
+		}
+
+		var sb strings.Builder
+		sb.WriteString(marker)
+		for j, cell := range cells {
+			sb.WriteString(cell)
+			if j < len(cells)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell)+2))
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatMatrixMarkdown formats a comparison matrix as a Markdown table.
+// Values are shown as code spans; the paths of rows where the files
+// disagree are shown in bold.
+func FormatMatrixMarkdown(matrix models.Matrix) string {
+	var sb strings.Builder
+	sb.WriteString("| key |")
+	for _, file := range matrix.Files {
+		sb.WriteString(" " + escapeMarkdownCell(file) + " |")
+	}
+	sb.WriteString("\n|---|")
+	sb.WriteString(strings.Repeat("---|", len(matrix.Files)))
+
+	for _, row := range matrix.Rows {
+		path := escapeMarkdownCell(row.Path)
+		if row.Diverges {
+			path = "**" + path + "**"
+		}
+		sb.WriteString("\n| " + path + " |")
+		for _, value := range row.Values {
+			cell := "*(absent)*"
+			if value != nil {
+				cell = "`" + escapeMarkdownCell(formatMatrixValue(value)) + "`"
+			}
+			sb.WriteString(" " + cell + " |")
+		}
+	}
+	return sb.String()
+}
+
+// FormatMatrixJSON formats a comparison matrix as a JSON object with the
+// list of "files" and one entry per key path in "rows". Each row holds its
+// "values" keyed by file, leaving out the files the key is absent from.
+func FormatMatrixJSON(matrix models.Matrix) (string, error) {
+	rows := make([]map[string]any, 0, len(matrix.Rows))
+	for _, row := range matrix.Rows {
+		values := make(map[string]any, len(row.Values))
+		for i, value := range row.Values {
+			if value != nil {
+				values[matrix.Files[i]] = *value
+			}
+		}
+		rows = append(rows, map[string]any{
+			"path":     row.Path,
+			"values":   values,
+			"diverges": row.Diverges,
+		})
+	}
+
+	bytes, err := json.MarshalIndent(map[string]any{"files": matrix.Files, "rows": rows}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+func formatMatrixValue(value *any) string {
+	if value == nil {
+		return absentValue
+	}
+	return formatValue(*value, 0)
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
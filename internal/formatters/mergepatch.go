@@ -0,0 +1,101 @@
+package formatters
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"fmt"
+)
+
+// FormatMergePatch formats a diff tree as an RFC 7386 JSON Merge Patch: the
+// smallest document that, merged into the first file, yields the second.
+//   - Removed keys are set to null
+//   - Added and changed keys carry their new value
+//   - Nested objects recurse and are left out when nothing changed inside
+//   - Arrays with any change are replaced as a whole, with their new value
+//     in full: elements left out of the diff by ignore or only patterns are
+//     written too
+//   - Values equivalent after coercion are left out
+//   - Dates and times are written as their RFC 3339 text
+//
+// A merge patch cannot set a value, or a member of a new object, to null
+// since null means removal; such diffs are reported as an error.
+func FormatMergePatch(nodes []models.DiffNode) (string, error) {
+	patch, err := mergePatchObject(nodes, "")
+	if err != nil {
+		return "", err
+	}
+	out, err := json.MarshalIndent(patch, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func mergePatchObject(nodes []models.DiffNode, parentPath string) (map[string]any, error) {
+	patch := make(map[string]any)
+	for _, node := range nodes {
+		path := buildPath(parentPath, node.Key)
+
+		switch node.Type {
+		case models.NodeTypeRemoved:
+			patch[node.Key] = nil
+		case models.NodeTypeAdded, models.NodeTypeChanged, models.NodeTypeTypeChanged:
+			if hasObjectNull(node.NewValue) {
+				return nil, fmt.Errorf("merge patch cannot set '%s' to a value holding null", path)
+			}
+			patch[node.Key] = patchValueOf(node.NewValue)
+		case models.NodeTypeNested:
+			child, err := mergePatchObject(node.Children, path)
+			if err != nil {
+				return nil, err
+			}
+			if len(child) > 0 {
+				patch[node.Key] = child
+			}
+		case models.NodeTypeArray:
+			if hasChanges(node.Children) {
+				patch[node.Key] = patchValueOf(node.NewValue)
+			}
+		}
+	}
+	return patch, nil
+}
+
+// hasObjectNull reports whether the value is null or an object with a null
+// member at any depth. Nulls inside arrays survive a merge and are fine.
+func hasObjectNull(value any) bool {
+	if value == nil {
+		return true
+	}
+	if m, ok := value.(map[string]any); ok {
+		for _, v := range m {
+			if hasObjectNull(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// patchValueOf copies a value for a patch document, writing dates and times
+// as the plain strings a patch can carry instead of the {"kind", "value"}
+// objects of the json format.
+func patchValueOf(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = patchValueOf(item)
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, item := range v {
+			l[i] = patchValueOf(item)
+		}
+		return l
+	case models.DateTime:
+		return v.Value
+	}
+	return value
+}
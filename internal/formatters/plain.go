@@ -3,6 +3,7 @@ package formatters
 import (
 	"code/internal/models"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -12,38 +13,62 @@ import (
 //   - Added properties: "Property 'path' was added with value: X"
 //   - Removed properties: "Property 'path' was removed"
 //   - Changed properties: "Property 'path' was updated. From X to Y"
+//   - Properties changing between object, array and scalar:
+//     "Property 'path' changed type from string to object"
+//   - Equivalent properties: "Property 'path' is equivalent after coercion. From X to Y"
 //   - Unchanged properties are not shown
 //   - Nested objects show full path separated by dots (e.g., 'common.setting6.ops')
-//   - Complex values (objects) are shown as [complex value]
+//   - Array elements show their index in brackets (e.g., 'servers[2].host'),
+//     the index in the new list; removed elements show their index in the
+//     old list, followed by "(old index)"
+//   - Complex values (objects and arrays) are shown as [complex value]
 //   - String values are wrapped in single quotes
+//   - Dates and times are shown unquoted in RFC 3339 form
 //
 // The output is sorted alphabetically by property path.
 func FormatPlain(nodes []models.DiffNode) string {
-	lines := formatPlainNodes(nodes, "")
+	lines := formatPlainNodes(nodes, "", false)
 	return strings.Join(lines, "\n")
 }
 
-func formatPlainNodes(nodes []models.DiffNode, parentPath string) []string {
+func formatPlainNodes(nodes []models.DiffNode, parentPath string, inArray bool) []string {
 	var lines []string
 
 	for _, node := range nodes {
 		path := buildPath(parentPath, node.Key)
+		if inArray {
+			path = buildIndexPath(parentPath, node.Key)
+		}
 
 		switch node.Type {
 		case models.NodeTypeAdded:
 			lines = append(lines, fmt.Sprintf("Property '%s' was added with value: %s", path, formatPlainValue(node.NewValue)))
 
 		case models.NodeTypeRemoved:
-			lines = append(lines, fmt.Sprintf("Property '%s' was removed", path))
+			line := fmt.Sprintf("Property '%s' was removed", path)
+			if _, err := strconv.Atoi(node.Key); inArray && err == nil {
+				line += " (old index)"
+			}
+			lines = append(lines, line)
 
 		case models.NodeTypeChanged:
 			lines = append(lines, fmt.Sprintf("Property '%s' was updated. From %s to %s",
 				path, formatPlainValue(node.OldValue), formatPlainValue(node.NewValue)))
 
+		case models.NodeTypeTypeChanged:
+			lines = append(lines, fmt.Sprintf("Property '%s' changed type from %s to %s", path, node.OldKind, node.NewKind))
+
+		case models.NodeTypeEquivalent:
+			lines = append(lines, fmt.Sprintf("Property '%s' is equivalent after coercion. From %s to %s",
+				path, formatPlainValue(node.OldValue), formatPlainValue(node.NewValue)))
+
 		case models.NodeTypeNested:
-			childLines := formatPlainNodes(node.Children, path)
+			childLines := formatPlainNodes(node.Children, path, false)
 			lines = append(lines, childLines...)
 
+		case models.NodeTypeArray:
+			childLines := formatPlainNodes(node.Children, path, true)
+			lines = append(lines, childLines...)
 		}
 	}
 
@@ -57,9 +82,16 @@ func buildPath(parentPath, key string) string {
 	return parentPath + "." + key
 }
 
+func buildIndexPath(parentPath, key string) string {
+	return parentPath + "[" + key + "]"
+}
+
 func isComplexValue(value any) bool {
-	_, ok := value.(map[string]any)
-	return ok
+	switch value.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
 }
 
 func formatPlainValue(value any) string {
@@ -75,6 +107,6 @@ func formatPlainValue(value any) string {
 		return "'" + str + "'"
 	}
 
-	// For numbers and booleans
+	// For numbers, booleans, dates and times
 	return fmt.Sprintf("%v", value)
 }
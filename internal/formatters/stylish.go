@@ -18,58 +18,86 @@ const (
 // and returns a human-readable string with proper indentation and markers:
 //   - Keys that were removed are prefixed with "- "
 //   - Keys that were added are prefixed with "+ "
-//   - Keys that were modified are shown as both removed and added
+//   - Keys that were modified, including changes of type, are shown as
+//     both removed and added
 //   - Keys that remain unchanged are prefixed with "  "
+//   - Keys equivalent after coercion are shown unchanged with their old
+//     value, followed by "(equivalent after coercion)"
 //   - Nested structures are properly indented with 4 spaces per level
+//   - Arrays present in both files are shown as [ ... ] with one marked
+//     line per element and no keys
 //
 // The output uses consistent formatting with alphabetically sorted keys
 // at each nesting level. Values are JSON-encoded to ensure proper representation
-// of strings, numbers, booleans, and null values.
+// of strings, numbers, booleans, and null values; dates and times are written
+// as their RFC 3339 text.
 func FormatStylish(nodes []models.DiffNode) string {
 	var sb strings.Builder
 	sb.WriteString("{\n")
-	formatNodes(nodes, 1, &sb)
+	formatNodes(nodes, 1, false, &sb)
 	sb.WriteString("}")
 	return sb.String()
 }
 
-func formatNodes(nodes []models.DiffNode, depth int, sb *strings.Builder) {
+func formatNodes(nodes []models.DiffNode, depth int, inArray bool, sb *strings.Builder) {
 	for _, node := range nodes {
+		label := node.Key + ": "
+		if inArray {
+			label = ""
+		}
+
 		switch node.Type {
 		case models.NodeTypeAdded:
-			writeNode(sb, depth, "+ ", node.Key, node.NewValue)
+			writeNode(sb, depth, "+ ", label, node.NewValue)
 		case models.NodeTypeRemoved:
-			writeNode(sb, depth, "- ", node.Key, node.OldValue)
-		case models.NodeTypeChanged:
-			writeNode(sb, depth, "- ", node.Key, node.OldValue)
-			writeNode(sb, depth, "+ ", node.Key, node.NewValue)
+			writeNode(sb, depth, "- ", label, node.OldValue)
+		case models.NodeTypeChanged, models.NodeTypeTypeChanged:
+			writeNode(sb, depth, "- ", label, node.OldValue)
+			writeNode(sb, depth, "+ ", label, node.NewValue)
 		case models.NodeTypeUnchanged:
-			writeNode(sb, depth, "  ", node.Key, node.OldValue)
+			writeNode(sb, depth, "  ", label, node.OldValue)
+		case models.NodeTypeEquivalent:
+			writeAnnotatedNode(sb, depth, "  ", label, node.OldValue, "equivalent after coercion")
 		case models.NodeTypeNested:
-			writeNestedNode(sb, depth, node.Key, node.Children)
+			writeNestedNode(sb, depth, label, "{", "}", node.Children, false)
+		case models.NodeTypeArray:
+			writeNestedNode(sb, depth, label, "[", "]", node.Children, true)
 		}
 	}
 }
 
-func writeNode(sb *strings.Builder, depth int, marker, key string, value any) {
+// writeNode writes a single marked line. The label is "key: " for object
+// members and empty for array elements, which are identified by position.
+func writeNode(sb *strings.Builder, depth int, marker, label string, value any) {
+	writeAnnotatedNode(sb, depth, marker, label, value, "")
+}
+
+// writeAnnotatedNode writes a marked line followed by a note in parentheses,
+// unless the note is empty.
+func writeAnnotatedNode(sb *strings.Builder, depth int, marker, label string, value any, note string) {
 	indent := strings.Repeat(" ", depth*indentSize-markerOffset)
 	sb.WriteString(indent)
 	sb.WriteString(marker)
-	sb.WriteString(key)
-	sb.WriteString(": ")
+	sb.WriteString(label)
 	sb.WriteString(formatValue(value, depth))
+	if note != "" {
+		sb.WriteString(" (" + note + ")")
+	}
 	sb.WriteString("\n")
 }
 
-func writeNestedNode(sb *strings.Builder, depth int, key string, children []models.DiffNode) {
+func writeNestedNode(sb *strings.Builder, depth int, label, open, close string, children []models.DiffNode, inArray bool) {
 	indent := strings.Repeat(" ", depth*indentSize-markerOffset)
 	sb.WriteString(indent)
 	sb.WriteString("  ")
-	sb.WriteString(key)
-	sb.WriteString(": {\n")
-	formatNodes(children, depth+1, sb)
+	sb.WriteString(label)
+	sb.WriteString(open)
+	sb.WriteString("\n")
+	formatNodes(children, depth+1, inArray, sb)
 	sb.WriteString(indent)
-	sb.WriteString("  }\n")
+	sb.WriteString("  ")
+	sb.WriteString(close)
+	sb.WriteString("\n")
 }
 
 func formatValue(value any, depth int) string {
@@ -85,6 +113,10 @@ func formatValue(value any, depth int) string {
 		return s
 	}
 
+	if d, ok := value.(models.DateTime); ok {
+		return d.String()
+	}
+
 	bytes, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Sprintf("%v", value)
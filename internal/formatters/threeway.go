@@ -0,0 +1,161 @@
+package formatters
+
+import (
+	"code/internal/models"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// absentValue stands for a key missing from one of the three files.
+const absentValue = "(absent)"
+
+// FormatThreeWay renders a three-way diff tree in the named format. Only
+// "stylish", "plain" and "json" support three-way diffs.
+func FormatThreeWay(nodes []models.ThreeWayNode, format string) (string, error) {
+	switch format {
+	case formatStylish:
+		return FormatThreeWayStylish(nodes), nil
+	case formatPlain:
+		return FormatThreeWayPlain(nodes), nil
+	case formatJson:
+		return FormatThreeWayJSON(nodes)
+	}
+	return "", fmt.Errorf("format %s does not support three-way diffs (available: %s, %s, %s)",
+		format, formatStylish, formatPlain, formatJson)
+}
+
+// FormatThreeWayStylish formats a three-way diff tree like the stylish
+// format, with one line per key and a marker telling where it changed:
+//   - "  " unchanged, shown with its value
+//   - "< " changed in ours only, shown as "base -> ours"
+//   - "> " changed in theirs only, shown as "base -> theirs"
+//   - "= " changed identically in both, shown as "base -> new value"
+//   - "! " conflicting changes, shown as "base -> ours: X, theirs: Y"
+//
+// Objects changed on either side are shown as nested blocks. A key missing
+// from a file is shown as (absent).
+func FormatThreeWayStylish(nodes []models.ThreeWayNode) string {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	formatThreeWayNodes(nodes, 1, &sb)
+	sb.WriteString("}")
+	return sb.String()
+}
+
+func formatThreeWayNodes(nodes []models.ThreeWayNode, depth int, sb *strings.Builder) {
+	indent := strings.Repeat(" ", depth*indentSize-markerOffset)
+	side := func(value *any) string {
+		if value == nil {
+			return absentValue
+		}
+		return formatValue(*value, depth)
+	}
+
+	for _, node := range nodes {
+		if node.Status == models.ThreeWayNested {
+			sb.WriteString(indent + "  " + node.Key + ": {\n")
+			formatThreeWayNodes(node.Children, depth+1, sb)
+			sb.WriteString(indent + "  }\n")
+			continue
+		}
+
+		marker, value := "  ", side(node.Base)
+		switch node.Status {
+		case models.ThreeWayOurs:
+			marker, value = "< ", side(node.Base)+" -> "+side(node.Ours)
+		case models.ThreeWayTheirs:
+			marker, value = "> ", side(node.Base)+" -> "+side(node.Theirs)
+		case models.ThreeWayBoth:
+			marker, value = "= ", side(node.Base)+" -> "+side(node.Ours)
+		case models.ThreeWayConflict:
+			marker, value = "! ", fmt.Sprintf("%s -> ours: %s, theirs: %s", side(node.Base), side(node.Ours), side(node.Theirs))
+		}
+		sb.WriteString(indent + marker + node.Key + ": " + value + "\n")
+	}
+}
+
+// FormatThreeWayPlain formats a three-way diff tree as one line per
+// changed property, telling on which side it changed:
+//   - "Property 'path' was updated in ours. From X to Y"
+//   - "Property 'path' was added in theirs with value: X"
+//   - "Property 'path' was removed in both"
+//   - "Property 'path' has conflicting changes. Base: X, ours: Y, theirs: Z"
+//
+// Unchanged properties are not shown; values are written like in the plain
+// format and missing ones as (absent).
+func FormatThreeWayPlain(nodes []models.ThreeWayNode) string {
+	lines := formatThreeWayPlainNodes(nodes, "")
+	return strings.Join(lines, "\n")
+}
+
+func formatThreeWayPlainNodes(nodes []models.ThreeWayNode, parentPath string) []string {
+	var lines []string
+
+	for _, node := range nodes {
+		path := buildPath(parentPath, node.Key)
+
+		switch node.Status {
+		case models.ThreeWayNested:
+			lines = append(lines, formatThreeWayPlainNodes(node.Children, path)...)
+		case models.ThreeWayOurs:
+			lines = append(lines, describeSideChange(path, "ours", node.Base, node.Ours))
+		case models.ThreeWayTheirs:
+			lines = append(lines, describeSideChange(path, "theirs", node.Base, node.Theirs))
+		case models.ThreeWayBoth:
+			lines = append(lines, describeSideChange(path, "both", node.Base, node.Ours))
+		case models.ThreeWayConflict:
+			lines = append(lines, fmt.Sprintf("Property '%s' has conflicting changes. Base: %s, ours: %s, theirs: %s",
+				path, formatPlainSide(node.Base), formatPlainSide(node.Ours), formatPlainSide(node.Theirs)))
+		}
+	}
+
+	return lines
+}
+
+func describeSideChange(path, side string, old, new *any) string {
+	switch {
+	case old == nil:
+		return fmt.Sprintf("Property '%s' was added in %s with value: %s", path, side, formatPlainSide(new))
+	case new == nil:
+		return fmt.Sprintf("Property '%s' was removed in %s", path, side)
+	}
+	return fmt.Sprintf("Property '%s' was updated in %s. From %s to %s",
+		path, side, formatPlainSide(old), formatPlainSide(new))
+}
+
+func formatPlainSide(value *any) string {
+	if value == nil {
+		return absentValue
+	}
+	return formatPlainValue(*value)
+}
+
+// FormatThreeWayJSON formats a three-way diff tree as a JSON object keyed
+// like the json format. Each entry has a "status" and the "base", "ours" and
+// "theirs" values, leaving out the ones absent from their file; nested
+// entries have "children" instead.
+func FormatThreeWayJSON(nodes []models.ThreeWayNode) (string, error) {
+	bytes, err := json.MarshalIndent(threeWayNodesToMap(nodes), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+func threeWayNodesToMap(nodes []models.ThreeWayNode) map[string]any {
+	result := make(map[string]any, len(nodes))
+	for _, node := range nodes {
+		entry := map[string]any{"status": node.Status}
+		if node.Status == models.ThreeWayNested {
+			entry["children"] = threeWayNodesToMap(node.Children)
+		}
+		for name, value := range map[string]*any{"base": node.Base, "ours": node.Ours, "theirs": node.Theirs} {
+			if value != nil {
+				entry[name] = *value
+			}
+		}
+		result[node.Key] = entry
+	}
+	return result
+}
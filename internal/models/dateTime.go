@@ -0,0 +1,39 @@
+package models
+
+import "encoding/json"
+
+// DateTimeKind tells apart the date and time flavours of input formats
+// with native temporal values. Names follow the TOML specification.
+type DateTimeKind string
+
+const (
+	// DateTimeOffset is a date and time with a UTC offset
+	DateTimeOffset DateTimeKind = "offset-datetime"
+	// DateTimeLocal is a date and time without an offset
+	DateTimeLocal DateTimeKind = "local-datetime"
+	// DateLocal is a date without a time
+	DateLocal DateTimeKind = "local-date"
+	// TimeLocal is a time of day without a date
+	TimeLocal DateTimeKind = "local-time"
+)
+
+// DateTime is a date and/or time value decoded from a file. It is kept in
+// its RFC 3339 textual form so that it compares with == and renders the way
+// it was written instead of as a quoted string.
+type DateTime struct {
+	Kind  DateTimeKind
+	Value string
+}
+
+func (d DateTime) String() string {
+	return d.Value
+}
+
+// MarshalJSON encodes the value as {"kind": ..., "value": ...} so that
+// consumers of the JSON output can tell it apart from a plain string.
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{
+		"kind":  string(d.Kind),
+		"value": d.Value,
+	})
+}
@@ -12,15 +12,52 @@ const (
 	NodeTypeChanged NodeType = "changed"
 	// NodeTypeUnchanged represents a key whose value remained the same
 	NodeTypeUnchanged NodeType = "unchanged"
-	// NodeTypeNested represents a key whose value is a nested object in both files
+	// NodeTypeNested represents a key whose value is a nested object in both
+	// files; OldValue and NewValue hold both objects, its children the diff
+	// of their keys
 	NodeTypeNested NodeType = "nested"
+	// NodeTypeArray represents a key whose value is a list in both files;
+	// OldValue and NewValue hold both lists, its children are the element
+	// diffs keyed by index
+	NodeTypeArray NodeType = "array"
+	// NodeTypeEquivalent represents a key whose values differ in type but
+	// are equivalent after the coercions of the loose mode, e.g. "8080" and
+	// 8080, or null and a missing key (both values are then null)
+	NodeTypeEquivalent NodeType = "equivalent"
+	// NodeTypeTypeChanged represents a key whose value changed between an
+	// object, an array and a scalar; OldKind and NewKind name the two kinds
+	NodeTypeTypeChanged NodeType = "typeChanged"
 )
 
 // DiffNode represents a single node in the diff tree
 type DiffNode struct {
-	Key      string      `json:"key"`
-	Type     NodeType    `json:"type"`
-	OldValue any         `json:"oldValue,omitempty"`
-	NewValue any         `json:"newValue,omitempty"`
-	Children []DiffNode  `json:"children,omitempty"`
+	Key      string     `json:"key"`
+	Type     NodeType   `json:"type"`
+	OldValue any        `json:"oldValue,omitempty"`
+	NewValue any        `json:"newValue,omitempty"`
+	Children []DiffNode `json:"children,omitempty"`
+	OldKind  string     `json:"oldKind,omitempty"`
+	NewKind  string     `json:"newKind,omitempty"`
+}
+
+// KindOf names the kind of a decoded value: "object", "array", "string",
+// "number", "boolean", "datetime" or "null".
+func KindOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case DateTime:
+		return "datetime"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return "number"
+	}
+	return "unknown"
 }
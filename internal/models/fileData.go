@@ -1,6 +1,10 @@
 package models
 
+// FileData is the raw content of one input together with its format.
+// Path is where the content came from ("-" for stdin) and may be empty
+// for data that was not read from a file.
 type FileData struct {
+	Path    string
 	Content []byte
 	Format  string
 }
@@ -0,0 +1,25 @@
+package models
+
+// FileStatus tells what happened to a file between two directory trees
+type FileStatus string
+
+const (
+	// FileAdded represents a file present in the second tree only
+	FileAdded FileStatus = "added"
+	// FileRemoved represents a file present in the first tree only
+	FileRemoved FileStatus = "removed"
+	// FileChanged represents a file present in both trees with differences
+	FileChanged FileStatus = "changed"
+	// FileUnchanged represents a file present in both trees without differences
+	FileUnchanged FileStatus = "unchanged"
+)
+
+// FileDiff represents one file of a directory comparison. Nodes holds the
+// diff of a configuration file present in both trees; it is nil for files
+// present in one tree only and for files whose format is not recognised,
+// which are compared byte by byte.
+type FileDiff struct {
+	Path   string
+	Status FileStatus
+	Nodes  []DiffNode
+}
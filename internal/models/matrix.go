@@ -0,0 +1,20 @@
+package models
+
+// Matrix holds the values of every key path across several files
+type Matrix struct {
+	// Files names the compared files, one per column
+	Files []string `json:"files"`
+	// Rows holds one row per key path, sorted by path
+	Rows []MatrixRow `json:"rows"`
+}
+
+// MatrixRow represents the values of one key path in each file
+type MatrixRow struct {
+	// Path is the dotted path of the key
+	Path string `json:"path"`
+	// Values holds the value in each file, by file position;
+	// nil when the key is absent from that file
+	Values []*any `json:"values"`
+	// Diverges tells whether the files disagree on the value
+	Diverges bool `json:"diverges"`
+}
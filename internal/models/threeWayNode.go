@@ -0,0 +1,32 @@
+package models
+
+// ThreeWayStatus tells how a key changed in two files derived from a common base
+type ThreeWayStatus string
+
+const (
+	// ThreeWayUnchanged represents a key that changed in neither file
+	ThreeWayUnchanged ThreeWayStatus = "unchanged"
+	// ThreeWayOurs represents a key that changed in ours only
+	ThreeWayOurs ThreeWayStatus = "ours"
+	// ThreeWayTheirs represents a key that changed in theirs only
+	ThreeWayTheirs ThreeWayStatus = "theirs"
+	// ThreeWayBoth represents a key that changed identically in both files
+	ThreeWayBoth ThreeWayStatus = "both"
+	// ThreeWayConflict represents a key that changed differently in both files
+	ThreeWayConflict ThreeWayStatus = "conflict"
+	// ThreeWayNested represents a key whose value is an object in all three
+	// files and changed in at least one of them; its children tell the details
+	ThreeWayNested ThreeWayStatus = "nested"
+)
+
+// ThreeWayNode represents a single node in a three-way diff tree.
+// Base, Ours and Theirs point to the value in each file and are nil when
+// the key is absent from that file. They are not set on nested nodes.
+type ThreeWayNode struct {
+	Key      string         `json:"key"`
+	Status   ThreeWayStatus `json:"status"`
+	Base     *any           `json:"base,omitempty"`
+	Ours     *any           `json:"ours,omitempty"`
+	Theirs   *any           `json:"theirs,omitempty"`
+	Children []ThreeWayNode `json:"children,omitempty"`
+}
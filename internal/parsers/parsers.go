@@ -2,20 +2,96 @@ package parsers
 
 import (
 	"code"
+	"context"
 	"fmt"
 )
 
-// ParseByPaths reads JSON or YAML files from the given paths and generates a formatted
+// ParseByPaths reads JSON, YAML, TOML, INI, .properties or dotenv files from the given paths and generates a formatted
 // diff showing the differences between them. It expects exactly two file paths and
 // an output format string.
-// Supported file formats: .json, .yaml, .yml
-// Supported output formats: "stylish", "plain"
+// Supported file formats: .json, .yaml, .yml, .toml, .ini, .properties, .env
+// Files with another extension, or whose content does not match their
+// extension, are parsed as whichever of JSON, TOML, YAML or INI accepts it.
+// Supported output formats: those listed by code.Formats
 // Files can be of different formats (e.g., comparing JSON with YAML is supported).
+// A path of "-" reads standard input, whose content is parsed like a file
+// with an unknown extension. code.WithInputFormats overrides detection for
+// standard input and regular files alike.
+// Options such as code.WithArrayDiff or code.WithOptions are passed through
+// to code.GenDiff. Two directories are compared file by file, see
+// code.DiffDirs.
+// With code.WithThreeWay it expects exactly three paths instead, a base and
+// two files derived from it, and passes them to code.GenThreeWayDiff.
+// With code.WithMatrix it accepts two or more paths and passes them to
+// code.GenMatrix.
 // It returns a string containing the diff output and an error if file reading,
 // parsing, or formatting fails.
-func ParseByPaths(paths []string, format string) (string, error) {
+func ParseByPaths(paths []string, format string, opts ...code.Option) (string, error) {
+	out, _, err := CompareByPaths(paths, format, opts...)
+	return out, err
+}
+
+// CompareByPaths is ParseByPaths that also reports whether the files differ:
+// any added, removed or changed value in a diff, any added, removed or
+// changed file between two directories, any change of ours or theirs in a
+// three-way diff, any diverging row in a matrix.
+func CompareByPaths(paths []string, format string, opts ...code.Option) (string, bool, error) {
+	opts = append([]code.Option{code.WithFormat(format)}, opts...)
+	var options code.Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Format == "" {
+		options.Format = "stylish"
+	}
+
+	if options.ThreeWay {
+		if len(paths) != 3 {
+			return "", false, fmt.Errorf("expected exactly 3 paths for a three-way diff, got %d", len(paths))
+		}
+		nodes, err := code.DiffThreeWayFiles(paths[0], paths[1], paths[2], opts...)
+		if err != nil {
+			return "", false, err
+		}
+		out, err := code.FormatThreeWay(nodes, options.Format)
+		return out, code.HasThreeWayChanges(nodes), err
+	}
+	if options.Matrix {
+		if len(paths) < 2 {
+			return "", false, fmt.Errorf("expected at least 2 paths for a comparison matrix, got %d", len(paths))
+		}
+		matrix, err := code.DiffMatrixFiles(paths, opts...)
+		if err != nil {
+			return "", false, err
+		}
+		out, err := code.FormatMatrix(matrix, options.Format)
+		return out, matrixDiverges(matrix), err
+	}
+
 	if len(paths) != 2 {
-		return "", fmt.Errorf("expected exactly 2 paths, got %d", len(paths))
+		return "", false, fmt.Errorf("expected exactly 2 paths, got %d", len(paths))
+	}
+	if code.IsDir(paths[0]) && code.IsDir(paths[1]) {
+		files, _, err := code.DiffDirs(context.Background(), paths[0], paths[1], opts...)
+		if err != nil {
+			return "", false, err
+		}
+		out, err := code.FormatDir(files, options.Format)
+		return out, code.HasFileChanges(files), err
+	}
+	nodes, err := code.DiffFiles(context.Background(), paths[0], paths[1], opts...)
+	if err != nil {
+		return "", false, err
+	}
+	out, err := code.Format(nodes, options.Format)
+	return out, code.HasChanges(nodes), err
+}
+
+func matrixDiverges(matrix code.Matrix) bool {
+	for _, row := range matrix.Rows {
+		if row.Diverges {
+			return true
+		}
 	}
-	return code.GenDiff(paths[0], paths[1], format)
+	return false
 }
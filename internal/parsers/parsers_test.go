@@ -1,6 +1,7 @@
 package parsers
 
 import (
+	"code"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -93,6 +94,16 @@ func TestParseByPaths(t *testing.T) {
 			paths: []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file2.yaml"},
 			want:  "{\n  - follow: false\n    host: hexlet.io\n  - proxy: 123.234.53.22\n  - timeout: 50\n  + timeout: 20\n  + verbose: true\n}",
 		},
+		{
+			name:  "different toml files with additions and deletions",
+			paths: []string{"../../testdata/fixture/file1.toml", "../../testdata/fixture/file2.toml"},
+			want:  "{\n  - follow: false\n    host: hexlet.io\n  - proxy: 123.234.53.22\n  - timeout: 50\n  + timeout: 20\n  + verbose: true\n}",
+		},
+		{
+			name:  "toml vs yaml mixed format",
+			paths: []string{"../../testdata/fixture/file1.toml", "../../testdata/fixture/file1.yaml"},
+			want:  "{\n    follow: false\n    host: hexlet.io\n    proxy: 123.234.53.22\n    timeout: 50\n}",
+		},
 		{
 			name:  "nested JSON files",
 			paths: []string{"../../testdata/fixture/nested.json", "../../testdata/fixture/nested2.json"},
@@ -121,3 +132,119 @@ func TestParseByPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestParseByPathsThreeWay(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "changed in ours only",
+			paths: []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file2.json", "../../testdata/fixture/file1.json"},
+			want:  "Property 'follow' was removed in ours\nProperty 'proxy' was removed in ours\nProperty 'timeout' was updated in ours. From 50 to 20\nProperty 'verbose' was added in ours with value: true",
+		},
+		{
+			name:  "changed identically in both",
+			paths: []string{"../../testdata/fixture/file1.toml", "../../testdata/fixture/file2.toml", "../../testdata/fixture/file2.yaml"},
+			want:  "Property 'follow' was removed in both\nProperty 'proxy' was removed in both\nProperty 'timeout' was updated in both. From 50 to 20\nProperty 'verbose' was added in both with value: true",
+		},
+		{
+			name:    "two paths",
+			paths:   []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file2.json"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			got, err := ParseByPaths(tt.paths, "plain", code.WithThreeWay())
+
+			if tt.wantErr {
+				r.Error(err)
+				return
+			}
+
+			r.NoError(err)
+			r.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestParseByPathsMatrix(t *testing.T) {
+	r := require.New(t)
+
+	got, err := ParseByPaths([]string{
+		"../../testdata/fixture/file1.yaml",
+		"../../testdata/fixture/file2.yaml",
+		"../../testdata/fixture/file1.toml",
+	}, "stylish", code.WithMatrix())
+	r.NoError(err)
+	r.Equal(`  key      ../../testdata/fixture/file1.yaml  ../../testdata/fixture/file2.yaml  ../../testdata/fixture/file1.toml
+! follow   false                              (absent)                           false
+  host     hexlet.io                          hexlet.io                          hexlet.io
+! proxy    123.234.53.22                      (absent)                           123.234.53.22
+! timeout  50                                 20                                 50
+! verbose  (absent)                           true                               (absent)`, got)
+
+	_, err = ParseByPaths([]string{"../../testdata/fixture/file1.yaml"}, "stylish", code.WithMatrix())
+	r.Error(err)
+}
+
+func TestCompareByPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		paths     []string
+		opts      []code.Option
+		wantDiffs bool
+	}{
+		{
+			name:  "identical files",
+			paths: []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file1.json"},
+		},
+		{
+			name:      "different files",
+			paths:     []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file2.json"},
+			wantDiffs: true,
+		},
+		{
+			name:  "three-way without changes",
+			paths: []string{"../../testdata/fixture/file1.yaml", "../../testdata/fixture/file1.yaml", "../../testdata/fixture/file1.yaml"},
+			opts:  []code.Option{code.WithThreeWay()},
+		},
+		{
+			name:      "three-way with changes",
+			paths:     []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file1.yaml", "../../testdata/fixture/file2.toml"},
+			opts:      []code.Option{code.WithThreeWay()},
+			wantDiffs: true,
+		},
+		{
+			name:  "matrix without divergence",
+			paths: []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file1.json"},
+			opts:  []code.Option{code.WithMatrix()},
+		},
+		{
+			name:      "matrix with divergence",
+			paths:     []string{"../../testdata/fixture/file1.json", "../../testdata/fixture/file1.yaml", "../../testdata/fixture/file2.yaml"},
+			opts:      []code.Option{code.WithMatrix()},
+			wantDiffs: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := require.New(t)
+
+			_, differs, err := CompareByPaths(tt.paths, "", tt.opts...)
+			r.NoError(err)
+			r.Equal(tt.wantDiffs, differs)
+		})
+	}
+
+	_, differs, err := CompareByPaths([]string{"../../testdata/fixture/nonexistent.json", "../../testdata/fixture/file1.json"}, "stylish")
+	require.Error(t, err)
+	require.False(t, differs)
+}
@@ -0,0 +1,94 @@
+package code
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Coercion is an equivalence rule of the loose comparison mode: values of
+// different types that it relates are reported as equivalent rather than
+// changed.
+type Coercion string
+
+const (
+	// CoerceStringNumber relates a string holding a number to that number,
+	// e.g. "8080" and 8080
+	CoerceStringNumber Coercion = "string-number"
+	// CoerceBoolString relates the strings "true" and "false", in any case,
+	// to the booleans
+	CoerceBoolString Coercion = "bool-string"
+	// CoerceNullAbsent relates a key set to null to a missing key
+	CoerceNullAbsent Coercion = "null-absent"
+	// CoerceEmptyNull relates the empty string to null
+	CoerceEmptyNull Coercion = "empty-null"
+)
+
+// Coercions returns every equivalence rule, the set WithLoose applies when
+// given none.
+func Coercions() []Coercion {
+	return []Coercion{CoerceStringNumber, CoerceBoolString, CoerceNullAbsent, CoerceEmptyNull}
+}
+
+func validCoercion(c Coercion) error {
+	for _, known := range Coercions() {
+		if c == known {
+			return nil
+		}
+	}
+	names := make([]string, 0, len(Coercions()))
+	for _, known := range Coercions() {
+		names = append(names, string(known))
+	}
+	return fmt.Errorf("unknown loose rule: %s (available: %s)", c, strings.Join(names, ", "))
+}
+
+// coerces reports whether the loose rule c is enabled.
+func (o Options) coerces(c Coercion) bool {
+	for _, rule := range o.Loose {
+		if rule == c {
+			return true
+		}
+	}
+	return false
+}
+
+// strict returns the options with every loose rule disabled.
+func (o Options) strict() Options {
+	o.Loose = nil
+	return o
+}
+
+// scalarsEquivalent compares two scalars of different types under the
+// enabled loose rules.
+func scalarsEquivalent(a, b any, opts Options) bool {
+	if s, ok := a.(string); ok {
+		return stringEquivalent(s, b, opts)
+	}
+	if s, ok := b.(string); ok {
+		return stringEquivalent(s, a, opts)
+	}
+	return false
+}
+
+func stringEquivalent(s string, other any, opts Options) bool {
+	switch v := other.(type) {
+	case nil:
+		return s == "" && opts.coerces(CoerceEmptyNull)
+	case bool:
+		return opts.coerces(CoerceBoolString) && strings.EqualFold(s, strconv.FormatBool(v))
+	}
+	if !opts.coerces(CoerceStringNumber) {
+		return false
+	}
+	if i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+		equal, _ := numbersEqual(i, other, opts.FloatTolerance)
+		return equal
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return false
+	}
+	equal, _ := numbersEqual(f, other, opts.FloatTolerance)
+	return equal
+}
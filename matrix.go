@@ -0,0 +1,117 @@
+package code
+
+import (
+	"code/internal/formatters"
+	"code/internal/models"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GenMatrix compares any number of configuration files, such as one per
+// environment, and renders every key path with its value in each file,
+// highlighting the paths where the files diverge.
+//
+// Parameters:
+//   - paths: paths to two or more configuration files, one of them may be "-"
+//   - format: output format ("stylish", "markdown", or "json")
+//   - opts: optional behaviour settings; WithInputFormats sets the formats
+//     of the files by position
+func GenMatrix(paths []string, format string, opts ...Option) (string, error) {
+	options, err := newOptions(append([]Option{WithFormat(format)}, opts...))
+	if err != nil {
+		return "", err
+	}
+	matrix, err := DiffMatrixFiles(paths, WithOptions(options))
+	if err != nil {
+		return "", err
+	}
+	return formatters.FormatMatrix(matrix, options.Format)
+}
+
+// DiffMatrixFiles reads and decodes the files at paths and returns their
+// comparison matrix without formatting it. Columns are named after the
+// paths, "stdin" for "-".
+func DiffMatrixFiles(paths []string, opts ...Option) (Matrix, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	filesData, err := readFiles(paths, options)
+	if err != nil {
+		return Matrix{}, err
+	}
+	docs, err := decodeFiles(filesData, options)
+	if err != nil {
+		return Matrix{}, err
+	}
+
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = path
+		if path == stdinPath {
+			names[i] = "stdin"
+		}
+	}
+	return DiffMatrix(docs, names, WithOptions(options))
+}
+
+// DiffMatrix builds the comparison matrix of decoded documents, naming the
+// column of each document after the matching entry of names. Objects are
+// walked down to their leaves; arrays are compared as a whole.
+func DiffMatrix(docs []map[string]any, names []string, opts ...Option) (Matrix, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return Matrix{}, err
+	}
+	if len(docs) != len(names) {
+		return Matrix{}, fmt.Errorf("got %d documents but %d names", len(docs), len(names))
+	}
+
+	leaves := make([]map[string]any, len(docs))
+	paths := make(map[string][]string)
+	for i, doc := range docs {
+		leaves[i] = make(map[string]any)
+		collectLeaves(doc, nil, leaves[i], paths)
+	}
+
+	keys := make([]string, 0, len(paths))
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	// Sorting by segments keeps the paths under one object together
+	slices.SortFunc(keys, func(a, b string) int {
+		return slices.Compare(paths[a], paths[b])
+	})
+
+	matrix := models.Matrix{Files: names, Rows: make([]models.MatrixRow, 0, len(keys))}
+	for _, key := range keys {
+		row := models.MatrixRow{Path: key, Values: make([]*any, len(docs))}
+		for i := range docs {
+			if value, ok := leaves[i][key]; ok {
+				row.Values[i] = valuePtr(value)
+			}
+			if i > 0 && !sameValue(row.Values[0], row.Values[i], options) {
+				row.Diverges = true
+			}
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	return matrix, nil
+}
+
+// collectLeaves stores every non-object value of doc, and every empty
+// object, under its dotted path, recording the segments of each path.
+func collectLeaves(doc map[string]any, path []string, leaves map[string]any, paths map[string][]string) {
+	for key, value := range doc {
+		segments := appendPath(path, key)
+		if m, ok := value.(map[string]any); ok && len(m) > 0 {
+			collectLeaves(m, segments, leaves, paths)
+			continue
+		}
+		joined := strings.Join(segments, ".")
+		leaves[joined] = value
+		paths[joined] = segments
+	}
+}
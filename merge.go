@@ -0,0 +1,141 @@
+package code
+
+import (
+	"code/internal/models"
+	"fmt"
+	"strings"
+)
+
+// MergeStrategy decides how Merge resolves keys changed differently in
+// ours and theirs.
+type MergeStrategy string
+
+const (
+	// MergeMarkers replaces each conflicting value with a conflict
+	// annotation, see Merge
+	MergeMarkers MergeStrategy = "markers"
+	// MergeOurs keeps the value from ours
+	MergeOurs MergeStrategy = "ours"
+	// MergeTheirs keeps the value from theirs
+	MergeTheirs MergeStrategy = "theirs"
+	// MergeFail makes Merge fail, listing every conflicting key
+	MergeFail MergeStrategy = "fail"
+)
+
+// conflictKey is the only key of a conflict annotation.
+const conflictKey = "$conflict"
+
+// Merge merges the changes that ours and theirs made to base. Keys changed
+// on one side only, or identically on both, take the changed value; objects
+// are merged key by key. Keys changed differently on both sides are
+// resolved by the strategy set with WithMergeStrategy. With the default
+// MergeMarkers such a key holds
+//
+//	{"$conflict": {"base": ..., "ours": ..., "theirs": ...}}
+//
+// leaving out the sides the key is absent from.
+//
+// Ignore and Only patterns only filter what a diff shows, and loose rules
+// and float tolerance only soften what it reports; none of them has an
+// effect here, every key and every edit of the inputs is merged.
+func Merge(base, ours, theirs map[string]any, opts ...Option) (map[string]any, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	merged, _, err := merge(base, ours, theirs, options)
+	return merged, err
+}
+
+// MergeFiles merges the configuration files at ours and theirs, both
+// derived from base, and returns the merged document encoded in the format
+// of base together with the dotted paths of the keys left with conflict
+// markers, none unless the strategy is MergeMarkers. One of the paths may
+// be "-" for stdin; WithInputFormats sets the formats of base, ours and
+// theirs in that order.
+func MergeFiles(base, ours, theirs string, opts ...Option) ([]byte, []string, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filesData, err := readFiles([]string{base, ours, theirs}, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	maps, err := decodeFiles(filesData, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged, conflicts, err := merge(maps[0], maps[1], maps[2], options)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := serializeFile(merged, filesData[0].Format)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, conflicts, nil
+}
+
+// merge is Merge that also returns the paths of the keys left with conflict
+// markers.
+func merge(base, ours, theirs map[string]any, options Options) (map[string]any, []string, error) {
+	options = options.unfiltered()
+
+	var conflicts []string
+	merged := mergeThreeWayTree(buildThreeWayDiff(base, ours, theirs, options), nil, options.MergeStrategy, &conflicts)
+	switch {
+	case options.MergeStrategy == MergeFail && len(conflicts) > 0:
+		return nil, nil, fmt.Errorf("%d merge conflicts: %s", len(conflicts), strings.Join(conflicts, ", "))
+	case options.MergeStrategy != MergeMarkers:
+		conflicts = nil
+	}
+	return merged, conflicts, nil
+}
+
+// mergeThreeWayTree builds the merged object for one level of a three-way
+// tree and appends the dotted paths of conflicting keys to conflicts.
+func mergeThreeWayTree(nodes []models.ThreeWayNode, path []string, strategy MergeStrategy, conflicts *[]string) map[string]any {
+	merged := make(map[string]any, len(nodes))
+	for _, node := range nodes {
+		var value *any
+		switch node.Status {
+		case models.ThreeWayNested:
+			value = valuePtr(mergeThreeWayTree(node.Children, appendPath(path, node.Key), strategy, conflicts))
+		case models.ThreeWayUnchanged:
+			value = node.Base
+		case models.ThreeWayOurs, models.ThreeWayBoth:
+			value = node.Ours
+		case models.ThreeWayTheirs:
+			value = node.Theirs
+		case models.ThreeWayConflict:
+			*conflicts = append(*conflicts, strings.Join(appendPath(path, node.Key), "."))
+			value = resolveConflict(node, strategy)
+		}
+		if value != nil {
+			merged[node.Key] = *value
+		}
+	}
+	return merged
+}
+
+// resolveConflict returns the merged value of a conflicting key, nil when
+// the key is left out.
+func resolveConflict(node models.ThreeWayNode, strategy MergeStrategy) *any {
+	switch strategy {
+	case MergeOurs:
+		return node.Ours
+	case MergeTheirs:
+		return node.Theirs
+	}
+
+	sides := make(map[string]any, 3)
+	for name, value := range map[string]*any{"base": node.Base, "ours": node.Ours, "theirs": node.Theirs} {
+		if value != nil {
+			sides[name] = *value
+		}
+	}
+	return valuePtr(map[string]any{conflictKey: sides})
+}
@@ -0,0 +1,139 @@
+package code
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Tolerance bounds how far apart two numbers may be and still compare
+// equal. Numbers are equal when their difference is at most Abs, or at
+// most Rel times the larger of their magnitudes. The zero Tolerance
+// requires exact equality.
+type Tolerance struct {
+	Abs float64
+	Rel float64
+}
+
+// ParseTolerance parses a tolerance such as "1e-9" (absolute), "0.1%"
+// (relative) or both separated by a comma, "1e-9,0.1%".
+func ParseTolerance(spec string) (Tolerance, error) {
+	var t Tolerance
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		percent, relative := strings.CutSuffix(part, "%")
+		value, err := strconv.ParseFloat(percent, 64)
+		if err != nil || value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+			return Tolerance{}, fmt.Errorf("invalid tolerance %q: expected a non-negative number or percentage", part)
+		}
+		if relative {
+			t.Rel = value / 100
+		} else {
+			t.Abs = value
+		}
+	}
+	return t, nil
+}
+
+// numbersEqual compares two numbers whatever Go type their parser gave
+// them, e.g. the float64 of JSON with the int of YAML. Integers are
+// compared exactly; as soon as one side is a float both are compared as
+// float64 within the tolerance. It reports false, false when either value
+// is not a number.
+func numbersEqual(a, b any, tolerance Tolerance) (equal, numeric bool) {
+	aInt, aIsInt := integerValue(a)
+	bInt, bIsInt := integerValue(b)
+	if aIsInt && bIsInt && tolerance == (Tolerance{}) {
+		return aInt == bInt, true
+	}
+
+	x, ok := floatValue(a)
+	if !ok {
+		return false, false
+	}
+	y, ok := floatValue(b)
+	if !ok {
+		return false, false
+	}
+	if x == y {
+		return true, true
+	}
+	diff := math.Abs(x - y)
+	return diff <= tolerance.Abs || diff <= tolerance.Rel*math.Max(math.Abs(x), math.Abs(y)), true
+}
+
+// integer is an integer of any decoded type, kept apart from floats so
+// that large values are compared without rounding.
+type integer struct {
+	negative  bool
+	magnitude uint64
+}
+
+func integerValue(value any) (integer, bool) {
+	var i int64
+	switch v := value.(type) {
+	case int:
+		i = int64(v)
+	case int8:
+		i = int64(v)
+	case int16:
+		i = int64(v)
+	case int32:
+		i = int64(v)
+	case int64:
+		i = v
+	case uint:
+		return integer{magnitude: uint64(v)}, true
+	case uint8:
+		return integer{magnitude: uint64(v)}, true
+	case uint16:
+		return integer{magnitude: uint64(v)}, true
+	case uint32:
+		return integer{magnitude: uint64(v)}, true
+	case uint64:
+		return integer{magnitude: v}, true
+	case float32, float64:
+		f, _ := floatValue(v)
+		if f != math.Trunc(f) || math.Abs(f) >= 1<<63 {
+			return integer{}, false
+		}
+		i = int64(f)
+	default:
+		return integer{}, false
+	}
+	if i < 0 {
+		return integer{negative: true, magnitude: uint64(-(i + 1)) + 1}, true
+	}
+	return integer{magnitude: uint64(i)}, true
+}
+
+func floatValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
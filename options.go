@@ -0,0 +1,440 @@
+package code
+
+import (
+	"code/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formatStylish is the default output format.
+const formatStylish = "stylish"
+
+// ArrayDiffMode selects how the elements of two lists are paired up
+// before they are compared.
+type ArrayDiffMode string
+
+const (
+	// ArrayDiffIndex compares elements that share the same position
+	ArrayDiffIndex ArrayDiffMode = "index"
+	// ArrayDiffLCS aligns both lists on their longest common subsequence,
+	// so inserted and deleted elements do not shift the rest of the list
+	ArrayDiffLCS ArrayDiffMode = "lcs"
+)
+
+// ArrayKey names the field that identifies the elements of the arrays
+// found at Path, e.g. Path "spec.containers" and Field "name".
+// Path segments may be "*" to match any key or index.
+type ArrayKey struct {
+	Path  string
+	Field string
+}
+
+// ParseArrayKey parses a "path=field" specification such as
+// "spec.containers=name".
+func ParseArrayKey(spec string) (ArrayKey, error) {
+	i := strings.LastIndex(spec, "=")
+	if i <= 0 || i == len(spec)-1 {
+		return ArrayKey{}, fmt.Errorf("invalid array key %q: expected path=field", spec)
+	}
+	return ArrayKey{Path: spec[:i], Field: spec[i+1:]}, nil
+}
+
+// Options holds the behaviour knobs of the diff engine. It is filled by
+// Option functions, or built as a whole (e.g. from command line flags) and
+// passed with WithOptions. Zero fields fall back to the defaults: stylish
+// output, arrays compared by index and merge conflicts marked in place.
+type Options struct {
+	// Format is the name of a registered output format, see Formats
+	Format string
+	// ArrayDiff selects how array elements are paired up
+	ArrayDiff ArrayDiffMode
+	// ArrayKeys match elements of specific arrays by an identity field
+	ArrayKeys []ArrayKey
+	// ExpandProperties nests dotted .properties keys ("db.host") into maps
+	ExpandProperties bool
+	// InputFormats overrides format detection per input, by position;
+	// an empty entry keeps detection for that input
+	InputFormats []string
+	// ThreeWay makes parsers.ParseByPaths expect a base and two derived
+	// files and report a three-way diff, see GenThreeWayDiff
+	ThreeWay bool
+	// Matrix makes parsers.ParseByPaths accept two or more files and
+	// render their comparison matrix, see GenMatrix
+	Matrix bool
+	// MergeStrategy resolves conflicting changes in Merge
+	MergeStrategy MergeStrategy
+	// Ignore lists paths left out of the comparison, as dotted paths with
+	// "*" and "**" wildcards or as JSON Pointers
+	Ignore []string
+	// Only restricts the comparison to the subtrees at these paths, given
+	// in the same syntax as Ignore; empty compares everything
+	Only []string
+	// FloatTolerance lets numbers differ slightly and still compare equal
+	FloatTolerance Tolerance
+	// Loose lists the coercions under which values of different types are
+	// reported as equivalent instead of changed; empty compares strictly
+	Loose []Coercion
+	// FailOnTypeChange makes the diff functions fail with ErrTypeChanged
+	// when a value changes between an object, an array and a scalar
+	FailOnTypeChange bool
+
+	// ignore and only hold the segments of the Ignore and Only patterns
+	ignore [][]string
+	only   [][]string
+}
+
+// Option configures Options.
+type Option func(*Options)
+
+// WithOptions replaces all settings made so far with o. Options given
+// after it still apply on top.
+func WithOptions(o Options) Option {
+	return func(dst *Options) {
+		*dst = o
+	}
+}
+
+// WithFormat selects the output format.
+func WithFormat(format string) Option {
+	return func(o *Options) {
+		o.Format = format
+	}
+}
+
+// WithArrayDiff selects the array alignment mode.
+func WithArrayDiff(mode ArrayDiffMode) Option {
+	return func(o *Options) {
+		o.ArrayDiff = mode
+	}
+}
+
+// WithArrayKey matches the elements of the arrays at path by the value of
+// their field instead of by position. It can be given several times.
+func WithArrayKey(path, field string) Option {
+	return func(o *Options) {
+		o.ArrayKeys = append(o.ArrayKeys, ArrayKey{Path: path, Field: field})
+	}
+}
+
+// WithExpandProperties nests dotted keys of .properties files into maps,
+// so "db.host" compares like the YAML key host under db.
+func WithExpandProperties() Option {
+	return func(o *Options) {
+		o.ExpandProperties = true
+	}
+}
+
+// WithInputFormats sets the format of the inputs by position instead of
+// detecting it from their paths, e.g. WithInputFormats("yaml", "") for
+// YAML on stdin compared with a file. A format is the name of a registered
+// input format (see InputFormats) or one of its extensions, with or without
+// the leading dot.
+func WithInputFormats(formats ...string) Option {
+	return func(o *Options) {
+		o.InputFormats = formats
+	}
+}
+
+// WithThreeWay compares a base file with two files derived from it instead
+// of comparing two files, see GenThreeWayDiff.
+func WithThreeWay() Option {
+	return func(o *Options) {
+		o.ThreeWay = true
+	}
+}
+
+// WithMatrix compares any number of files side by side instead of diffing
+// two of them, see GenMatrix.
+func WithMatrix() Option {
+	return func(o *Options) {
+		o.Matrix = true
+	}
+}
+
+// WithMergeStrategy selects how Merge resolves keys changed differently on
+// both sides.
+func WithMergeStrategy(strategy MergeStrategy) Option {
+	return func(o *Options) {
+		o.MergeStrategy = strategy
+	}
+}
+
+// WithIgnore leaves the values at the paths matching the patterns out of the
+// comparison, so they never show up in the diff. A pattern is a dotted path
+// such as "metadata.resourceVersion" or "spec.containers[*].image", where
+// "*" matches any one key or index and "**" any number of them, or a JSON
+// Pointer such as "/metadata/resourceVersion". It can be given several times.
+// Ignored array elements are shown unchanged. Formats that replace arrays
+// as a whole, such as mergepatch, still write their ignored elements.
+func WithIgnore(patterns ...string) Option {
+	return func(o *Options) {
+		o.Ignore = append(o.Ignore, patterns...)
+	}
+}
+
+// WithOnly restricts the comparison to the subtrees at the paths matching
+// the patterns, written as for WithIgnore. The keys leading to them are kept
+// so the diff still shows full paths and nesting. It can be given several
+// times.
+func WithOnly(patterns ...string) Option {
+	return func(o *Options) {
+		o.Only = append(o.Only, patterns...)
+	}
+}
+
+// WithFloatTolerance makes numbers compare equal when they are within the
+// tolerance of each other, e.g. Tolerance{Abs: 1e-9} so that
+// 0.30000000000000004 equals 0.3.
+func WithFloatTolerance(t Tolerance) Option {
+	return func(o *Options) {
+		o.FloatTolerance = t
+	}
+}
+
+// WithLoose reports values that differ only in type, such as "8080" and
+// 8080, as equivalent instead of changed, following the given rules or all
+// of Coercions when none are given.
+func WithLoose(rules ...Coercion) Option {
+	return func(o *Options) {
+		if len(rules) == 0 {
+			rules = Coercions()
+		}
+		o.Loose = append(o.Loose, rules...)
+	}
+}
+
+// WithFailOnTypeChange turns any change of a value between an object, an
+// array and a scalar into an ErrTypeChanged error, e.g. to enforce a schema
+// policy in CI.
+func WithFailOnTypeChange() Option {
+	return func(o *Options) {
+		o.FailOnTypeChange = true
+	}
+}
+
+// inputFormat returns the format override for the input at index i.
+func (o Options) inputFormat(i int) string {
+	if i < len(o.InputFormats) {
+		return o.InputFormats[i]
+	}
+	return ""
+}
+
+// arrayKeyField returns the identity field configured for the array at path.
+func (o Options) arrayKeyField(path []string) (string, bool) {
+	for _, k := range o.ArrayKeys {
+		if matchPath(splitPath(k.Path), path) {
+			return k.Field, true
+		}
+	}
+	return "", false
+}
+
+// ignored reports whether the value at path is left out of the comparison.
+func (o Options) ignored(path []string) bool {
+	for _, pattern := range o.ignore {
+		if matchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// unfiltered returns the options without Ignore and Only, loose rules and
+// float tolerance, for operations such as Merge whose output must keep every
+// value and every edit, however small.
+func (o Options) unfiltered() Options {
+	o.Ignore, o.Only = nil, nil
+	o.ignore, o.only = nil, nil
+	o.FloatTolerance = Tolerance{}
+	return o.strict()
+}
+
+// selected reports whether the value at path lies inside a subtree chosen
+// with Only. Everything is selected when Only is empty.
+func (o Options) selected(path []string) bool {
+	if len(o.only) == 0 {
+		return true
+	}
+	for _, pattern := range o.only {
+		if matchPathOrParent(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// reachable reports whether the value at path has to be compared: it is
+// not ignored and it is selected or leads to a selected subtree.
+func (o Options) reachable(path []string) bool {
+	if o.ignored(path) {
+		return false
+	}
+	if o.selected(path) {
+		return true
+	}
+	for _, pattern := range o.only {
+		if matchPathPrefix(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterNode returns the node compared at path as it belongs in the diff
+// tree, or false when it is left out. Nodes inside a selected subtree are
+// kept whole. Nodes that only lead to a selected subtree are kept when
+// something selected lies below them: objects and arrays with selected
+// children, and added, removed or retyped values cut down to their selected
+// parts with selectedValue.
+func (o Options) filterNode(path []string, node models.DiffNode) (models.DiffNode, bool) {
+	if !o.reachable(path) {
+		return node, false
+	}
+	if o.selected(path) {
+		return node, true
+	}
+
+	switch node.Type {
+	case models.NodeTypeNested:
+		return node, len(node.Children) > 0
+	case models.NodeTypeArray:
+		// Elements left out are unchanged placeholders, see keepNodes
+		for _, child := range node.Children {
+			childPath := appendPath(path, child.Key)
+			if o.reachable(childPath) && (child.Type != models.NodeTypeUnchanged || o.selected(childPath)) {
+				return node, true
+			}
+		}
+		return node, false
+	case models.NodeTypeAdded:
+		var ok bool
+		node.NewValue, ok = o.selectedValue(path, node.NewValue)
+		return node, ok
+	case models.NodeTypeRemoved:
+		var ok bool
+		node.OldValue, ok = o.selectedValue(path, node.OldValue)
+		return node, ok
+	case models.NodeTypeTypeChanged:
+		// The change of type is kept even when only one side holds a
+		// selected part; the other side is then shown empty, or as the
+		// scalar it was
+		oldVal, inOld := o.selectedValue(path, node.OldValue)
+		newVal, inNew := o.selectedValue(path, node.NewValue)
+		if !inOld && !inNew {
+			return node, false
+		}
+		if !inOld {
+			oldVal = emptyContainer(node.OldValue)
+		}
+		if !inNew {
+			newVal = emptyContainer(node.NewValue)
+		}
+		node.OldValue, node.NewValue = oldVal, newVal
+		return node, true
+	}
+	// Scalars have nothing below them to select
+	return node, false
+}
+
+// selectedValue cuts a value found at path down to the parts lying in the
+// selected subtrees and not ignored. It reports false when no part does.
+func (o Options) selectedValue(path []string, value any) (any, bool) {
+	if !o.reachable(path) {
+		return nil, false
+	}
+	if o.selected(path) {
+		return value, true
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		cut := make(map[string]any)
+		for key, item := range v {
+			if item, ok := o.selectedValue(appendPath(path, key), item); ok {
+				cut[key] = item
+			}
+		}
+		return cut, len(cut) > 0
+	case []any:
+		var cut []any
+		for i, item := range v {
+			if item, ok := o.selectedValue(appendPath(path, strconv.Itoa(i)), item); ok {
+				cut = append(cut, item)
+			}
+		}
+		return cut, len(cut) > 0
+	}
+	return nil, false
+}
+
+// emptyContainer returns an empty object or array for objects and arrays,
+// and scalars as they are.
+func emptyContainer(value any) any {
+	switch value.(type) {
+	case map[string]any:
+		return map[string]any{}
+	case []any:
+		return []any{}
+	}
+	return value
+}
+
+func newOptions(opts []Option) (Options, error) {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.Format == "" {
+		o.Format = formatStylish
+	}
+	if o.ArrayDiff == "" {
+		o.ArrayDiff = ArrayDiffIndex
+	}
+
+	switch o.ArrayDiff {
+	case ArrayDiffIndex, ArrayDiffLCS:
+	default:
+		return o, fmt.Errorf("unknown array diff mode: %s", o.ArrayDiff)
+	}
+
+	if o.MergeStrategy == "" {
+		o.MergeStrategy = MergeMarkers
+	}
+	switch o.MergeStrategy {
+	case MergeMarkers, MergeOurs, MergeTheirs, MergeFail:
+	default:
+		return o, fmt.Errorf("unknown merge strategy: %s", o.MergeStrategy)
+	}
+
+	formats := make([]string, len(o.InputFormats))
+	for i, name := range o.InputFormats {
+		if name == "" {
+			continue
+		}
+		format, err := normalizeInputFormat(name)
+		if err != nil {
+			return o, err
+		}
+		formats[i] = format
+	}
+	o.InputFormats = formats
+
+	for _, rule := range o.Loose {
+		if err := validCoercion(rule); err != nil {
+			return o, err
+		}
+	}
+
+	var err error
+	if o.ignore, err = parsePathPatterns(o.Ignore); err != nil {
+		return o, err
+	}
+	if o.only, err = parsePathPatterns(o.Only); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
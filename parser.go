@@ -0,0 +1,283 @@
+package code
+
+import (
+	"bytes"
+	"code/internal/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parser decodes the content of one input format into a document.
+// Values should be of the kinds encoding/json or gopkg.in/yaml.v3 produce
+// so that documents of different formats compare with each other.
+type Parser interface {
+	Parse(data []byte) (map[string]any, error)
+}
+
+// ParserFunc adapts an ordinary function to the Parser interface.
+type ParserFunc func(data []byte) (map[string]any, error)
+
+// Parse calls f(data).
+func (f ParserFunc) Parse(data []byte) (map[string]any, error) {
+	return f(data)
+}
+
+// Serializer encodes a document back into its input format. A Parser that
+// also implements Serializer can be the base of "gendiff apply".
+type Serializer interface {
+	Serialize(doc map[string]any) ([]byte, error)
+}
+
+// codec is a built-in format that can be both parsed and written.
+type codec struct {
+	parse     func(data []byte) (map[string]any, error)
+	serialize func(doc map[string]any) ([]byte, error)
+}
+
+func (c codec) Parse(data []byte) (map[string]any, error) {
+	return c.parse(data)
+}
+
+func (c codec) Serialize(doc map[string]any) ([]byte, error) {
+	return c.serialize(doc)
+}
+
+// Built-in input formats
+const (
+	inputJSON       = "json"
+	inputYAML       = "yaml"
+	inputTOML       = "toml"
+	inputINI        = "ini"
+	inputProperties = "properties"
+	inputDotenv     = "dotenv"
+)
+
+var (
+	parsersMu sync.RWMutex
+	// parsers maps lower-case format names to their parser
+	parsers = make(map[string]Parser)
+	// extensions maps lower-case file extensions to format names
+	extensions = make(map[string]string)
+)
+
+func init() {
+	mustRegisterParser(inputJSON, codec{parseJSON, serializeJSON}, ".json")
+	mustRegisterParser(inputYAML, codec{parseYAML, serializeYAML}, ".yaml", ".yml")
+	mustRegisterParser(inputTOML, codec{parseTOML, serializeTOML}, ".toml")
+	mustRegisterParser(inputINI, codec{parseINI, serializeINI}, ".ini")
+	mustRegisterParser(inputProperties, codec{parseProperties, serializeProperties}, ".properties")
+	mustRegisterParser(inputDotenv, codec{parseDotenv, serializeDotenv}, ".env")
+}
+
+// RegisterParser makes p available as the input format name. The name may
+// be a short one like "hcl" or a MIME-like one like "application/hcl"; it
+// selects the parser in WithInputFormats and DiffReaders, case-insensitively.
+// Files whose path ends with one of the extensions (e.g. ".hcl") are parsed
+// with p when no format is given. It fails when the name is empty or taken,
+// or an extension does not start with "." or is already claimed.
+func RegisterParser(name string, p Parser, exts ...string) error {
+	key := strings.ToLower(name)
+	if key == "" {
+		return fmt.Errorf("parser name is empty")
+	}
+	if p == nil {
+		return fmt.Errorf("parser %s is nil", name)
+	}
+
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	if _, exists := parsers[key]; exists {
+		return fmt.Errorf("parser %s is already registered", name)
+	}
+	for _, ext := range exts {
+		if !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("extension %q of parser %s must start with a dot", ext, name)
+		}
+		if owner, exists := extensions[strings.ToLower(ext)]; exists {
+			return fmt.Errorf("extension %s is already registered for %s", ext, owner)
+		}
+	}
+
+	parsers[key] = p
+	for _, ext := range exts {
+		extensions[strings.ToLower(ext)] = key
+	}
+	return nil
+}
+
+func mustRegisterParser(name string, p Parser, exts ...string) {
+	if err := RegisterParser(name, p, exts...); err != nil {
+		panic(err)
+	}
+}
+
+// InputFormats returns the names of all registered input formats, sorted.
+func InputFormats() []string {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupParser resolves a format given by name ("yaml"), by extension
+// (".yml") or by extension without the dot ("yml") to its registered name
+// and parser.
+func lookupParser(format string) (string, Parser, error) {
+	key := strings.ToLower(strings.TrimSpace(format))
+
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	if p, ok := parsers[key]; ok {
+		return key, p, nil
+	}
+	if !strings.HasPrefix(key, ".") {
+		key = "." + key
+	}
+	if name, ok := extensions[key]; ok {
+		return name, parsers[name], nil
+	}
+	return "", nil, fmt.Errorf("unknown input format: %s", format)
+}
+
+// formatByExtension returns the format registered for the longest extension
+// the path ends with.
+func formatByExtension(path string) (string, bool) {
+	lower := strings.ToLower(path)
+
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	best, name := "", ""
+	for ext, format := range extensions {
+		if strings.HasSuffix(lower, ext) && len(ext) > len(best) {
+			best, name = ext, format
+		}
+	}
+	return name, name != ""
+}
+
+// parseFile decodes data with the parser of the given format. A document
+// that decodes to nothing, such as an empty YAML file, is an empty map.
+func parseFile(data []byte, format string) (map[string]any, error) {
+	_, p, err := lookupParser(format)
+	if err != nil {
+		return nil, err
+	}
+	m, err := p.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = make(map[string]any)
+	}
+	return m, nil
+}
+
+// serializeFile encodes doc with the serializer of the given format.
+func serializeFile(doc map[string]any, format string) ([]byte, error) {
+	name, p, err := lookupParser(format)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := p.(Serializer)
+	if !ok {
+		return nil, fmt.Errorf("input format %s cannot be written", name)
+	}
+	return s.Serialize(doc)
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// scalarString renders a scalar for the text-only formats (INI, properties,
+// dotenv). Objects and arrays have no such form.
+func scalarString(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case map[string]any, []any:
+		return "", fmt.Errorf("nested values cannot be written in this format")
+	}
+	return fmt.Sprint(value), nil
+}
+
+func parseJSON(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func parseYAML(data []byte) (map[string]any, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := node.Decode(&m); err != nil {
+		return nil, err
+	}
+	normalizeYAMLValue(&node, m)
+	return m, nil
+}
+
+func serializeJSON(doc map[string]any) ([]byte, error) {
+	out, err := json.MarshalIndent(denormalizeJSONValue(deepCopy(doc)), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// denormalizeJSONValue turns models.DateTime into its plain text, in place.
+// JSON has no date type, and a configuration file should not hold the
+// {"kind", "value"} objects of the json output format.
+func denormalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = denormalizeJSONValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = denormalizeJSONValue(item)
+		}
+	case models.DateTime:
+		return v.Value
+	}
+	return value
+}
+
+func serializeYAML(doc map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(denormalizeYAMLValue(deepCopy(doc))); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,420 @@
+package code
+
+import (
+	"bytes"
+	"code/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ApplyPatch applies a patch to doc and returns the patched document; doc is
+// left untouched. The patch is a JSON document: an array is an RFC 6902
+// JSON Patch, an object an RFC 7386 JSON Merge Patch.
+func ApplyPatch(doc map[string]any, patch []byte) (map[string]any, error) {
+	decoded, err := decodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	switch p := decoded.(type) {
+	case []any:
+		return applyJSONPatch(deepCopy(doc).(map[string]any), p)
+	case map[string]any:
+		return mergePatch(deepCopy(doc), p).(map[string]any), nil
+	}
+	return nil, fmt.Errorf("patch must be a JSON array (JSON Patch) or object (JSON Merge Patch)")
+}
+
+// ApplyPatchFiles applies the JSON Patch or JSON Merge Patch stored at
+// patchPath to the configuration file at path and returns the patched
+// document encoded in the file's own format. Either path may be "-" for
+// stdin. The file's format is detected like for GenDiff; the first entry of
+// WithInputFormats overrides it.
+func ApplyPatchFiles(path, patchPath string, opts ...Option) ([]byte, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if path == stdinPath && patchPath == stdinPath {
+		return nil, fmt.Errorf("only one file can be read from stdin")
+	}
+
+	fd, err := readFile(path, options.inputFormat(0))
+	if err != nil {
+		return nil, err
+	}
+	maps, err := decodeFiles([]models.FileData{fd}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var patch []byte
+	if patchPath == stdinPath {
+		patch, err = io.ReadAll(stdin)
+	} else {
+		patch, err = os.ReadFile(patchPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	patched, err := ApplyPatch(maps[0], patch)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", patchPath, err)
+	}
+	return serializeFile(patched, fd.Format)
+}
+
+// decodePatch decodes a JSON patch document. Integer literals become int
+// rather than float64 so that formats with an integer type, such as TOML,
+// write them back as integers.
+func decodePatch(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return convertNumbers(v)
+}
+
+func convertNumbers(value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			converted, err := convertNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = converted
+		}
+	case []any:
+		for i, item := range v {
+			converted, err := convertNumbers(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	case json.Number:
+		if i, err := strconv.Atoi(v.String()); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	}
+	return value, nil
+}
+
+// mergePatch implements the MergePatch function of RFC 7386, reusing
+// target's maps. A string replacing a date or time of the same format keeps
+// its kind, see keepDateTime.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return keepDateTime(target, patch)
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// keepDateTime returns value as a date or time of old's kind when old is one
+// and value is a string in the same format. Patches carry dates and times as
+// plain strings, so replacing one would otherwise turn it into a string.
+func keepDateTime(old, value any) any {
+	d, ok := old.(models.DateTime)
+	if !ok {
+		return value
+	}
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	updated := models.DateTime{Kind: d.Kind, Value: s}
+	if _, err := parseDateTime(updated); err != nil {
+		return value
+	}
+	return updated
+}
+
+// applyJSONPatch applies RFC 6902 operations in order, modifying doc.
+// The whole patch fails if one operation does, including a failed "test".
+func applyJSONPatch(doc map[string]any, ops []any) (map[string]any, error) {
+	var root any = doc
+	for i, raw := range ops {
+		op, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("operation %d is not an object", i)
+		}
+		var err error
+		if root, err = applyOperation(root, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%v %v): %w", i, op["op"], op["path"], err)
+		}
+	}
+
+	result, ok := root.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("patched document is not an object")
+	}
+	return result, nil
+}
+
+func applyOperation(root any, op map[string]any) (any, error) {
+	path, err := pointerMember(op, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	switch op["op"] {
+	case "add":
+		value, ok := op["value"]
+		if !ok {
+			return nil, fmt.Errorf("missing value")
+		}
+		return pointerAdd(root, path, value)
+	case "remove":
+		root, _, err = pointerRemove(root, path)
+		return root, err
+	case "replace":
+		value, ok := op["value"]
+		if !ok {
+			return nil, fmt.Errorf("missing value")
+		}
+		if len(path) == 0 {
+			// The whole document is replaced
+			return value, nil
+		}
+		var replaced any
+		if root, replaced, err = pointerRemove(root, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, keepDateTime(replaced, value))
+	case "move", "copy":
+		from, err := pointerMember(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		if op["op"] == "move" {
+			if isPointerPrefix(from, path) {
+				return nil, fmt.Errorf("cannot move a value into itself")
+			}
+			root, value, err = pointerRemove(root, from)
+		} else {
+			value, err = pointerGet(root, from)
+			value = deepCopy(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, value)
+	case "test":
+		value, err := pointerGet(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(value, op["value"]) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("unknown operation")
+}
+
+// pointerMember parses the JSON Pointer held by the named member of op.
+func pointerMember(op map[string]any, name string) ([]string, error) {
+	s, ok := op[name].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	if s == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+func isPointerPrefix(prefix, path []string) bool {
+	if len(prefix) >= len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex parses an array reference token. With allowEnd, "-" and len
+// address the position after the last element.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func pointerGet(node any, tokens []string) (any, error) {
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			node = child
+		case []any:
+			i, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("cannot descend into a scalar at %q", token)
+		}
+	}
+	return node, nil
+}
+
+// pointerAdd adds value at tokens and returns the updated node. Arrays are
+// returned as new slices, so every level stores the result of the level
+// below.
+func pointerAdd(node any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token, last := tokens[0], len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		if last {
+			n[token] = value
+			return n, nil
+		}
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+		updated, err := pointerAdd(child, tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = updated
+		return n, nil
+	case []any:
+		i, err := arrayIndex(token, len(n), last)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			return n, nil
+		}
+		updated, err := pointerAdd(n[i], tokens[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = updated
+		return n, nil
+	}
+	return nil, fmt.Errorf("cannot descend into a scalar at %q", token)
+}
+
+// pointerRemove removes the value at tokens and returns the updated node
+// together with the removed value.
+func pointerRemove(node any, tokens []string) (any, any, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	token, last := tokens[0], len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q not found", token)
+		}
+		if last {
+			delete(n, token)
+			return n, child, nil
+		}
+		updated, removed, err := pointerRemove(child, tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = updated
+		return n, removed, nil
+	case []any:
+		i, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := n[i]
+			return append(n[:i:i], n[i+1:]...), removed, nil
+		}
+		updated, removed, err := pointerRemove(n[i], tokens[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		n[i] = updated
+		return n, removed, nil
+	}
+	return nil, nil, fmt.Errorf("cannot descend into a scalar at %q", token)
+}
+
+// jsonEqual compares two values by their JSON encoding, so that numbers
+// decoded as int and as float64 compare equal.
+func jsonEqual(a, b any) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// deepCopy copies maps and slices so that patching never touches the input.
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = deepCopy(item)
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, item := range v {
+			l[i] = deepCopy(item)
+		}
+		return l
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package code
+
+import "strings"
+
+// splitPath splits a dotted path such as "spec.containers[0].image" into its
+// segments ("spec", "containers", "0", "image"). Bracketed segments are kept
+// verbatim, so "containers[name=web]" yields "containers", "name=web".
+func splitPath(path string) []string {
+	var segments []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '.':
+			flush()
+		case '[':
+			flush()
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				current.WriteString(path[i+1:])
+				i = len(path)
+				continue
+			}
+			segments = append(segments, path[i+1:i+end])
+			i += end
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return segments
+}
+
+// matchPath reports whether the path matches the pattern segment by segment.
+// A "*" pattern segment matches any single path segment, a "**" segment
+// matches any number of them, including none.
+func matchPath(pattern, path []string) bool {
+	for i, segment := range pattern {
+		if segment == "**" {
+			for j := i; j <= len(path); j++ {
+				if matchPath(pattern[i+1:], path[j:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if i >= len(path) || (segment != "*" && segment != path[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
+
+// matchPathPrefix reports whether the path leads towards the pattern, i.e.
+// whether it matches the pattern's first segments so that some deeper path
+// could match the pattern as a whole.
+func matchPathPrefix(pattern, path []string) bool {
+	for i, segment := range path {
+		if i >= len(pattern) {
+			return false
+		}
+		if pattern[i] == "**" {
+			return true
+		}
+		if pattern[i] != "*" && pattern[i] != segment {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPathOrParent reports whether the path or one of its parents matches
+// the pattern.
+func matchPathOrParent(pattern, path []string) bool {
+	for i := len(path); i >= 0; i-- {
+		if matchPath(pattern, path[:i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package code
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseProperties decodes a Java .properties document into a flat map of
+// strings. It follows java.util.Properties: "#" and "!" start comments, the
+// key ends at the first unescaped "=", ":" or whitespace, a trailing
+// backslash continues the logical line, and \t, \n, \r, \f, \uXXXX and
+// escaped separators are unescaped.
+func parseProperties(data []byte) (map[string]any, error) {
+	result := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var logical strings.Builder
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical.Len() == 0 && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		if continues(line) {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+
+		key, value, err := splitProperty(logical.String())
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+		logical.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if logical.Len() > 0 {
+		key, value, err := splitProperty(logical.String())
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// continues reports whether the line ends with an odd number of backslashes.
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("properties: malformed \\u escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("properties: malformed \\u escape in %q", s)
+			}
+			sb.WriteRune(rune(r))
+			i += 4
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// expandDottedKeys turns a flat map with dotted keys such as "db.host" into
+// nested maps. It fails when a key is both a value and a parent of other
+// keys, e.g. "db" and "db.host".
+func expandDottedKeys(flat map[string]any) (map[string]any, error) {
+	result := make(map[string]any)
+	for _, key := range sortedKeys(flat) {
+		segments := strings.Split(key, ".")
+		current := result
+		for i, segment := range segments[:len(segments)-1] {
+			next, exists := current[segment]
+			if !exists {
+				child := make(map[string]any)
+				current[segment] = child
+				current = child
+				continue
+			}
+			child, ok := next.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("property %q conflicts with %q", key, strings.Join(segments[:i+1], "."))
+			}
+			current = child
+		}
+
+		last := segments[len(segments)-1]
+		if _, exists := current[last]; exists {
+			return nil, fmt.Errorf("property %q conflicts with keys nested under it", key)
+		}
+		current[last] = flat[key]
+	}
+	return result, nil
+}
+
+// serializeProperties writes one "key=value" line per scalar, sorted by key.
+// Nested objects are flattened into dotted keys; arrays have no form.
+func serializeProperties(doc map[string]any) ([]byte, error) {
+	flat := make(map[string]any)
+	if err := flattenDotted(doc, "", flat); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, key := range sortedKeys(flat) {
+		s, err := scalarString(flat[key])
+		if err != nil {
+			return nil, fmt.Errorf("properties: %s: %w", key, err)
+		}
+		fmt.Fprintf(&buf, "%s=%s\n", escapeProperty(key, true), escapeProperty(s, false))
+	}
+	return buf.Bytes(), nil
+}
+
+func flattenDotted(m map[string]any, prefix string, flat map[string]any) error {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if child, ok := v.(map[string]any); ok {
+			if err := flattenDotted(child, key, flat); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, exists := flat[key]; exists {
+			return fmt.Errorf("properties: key %s is written twice", key)
+		}
+		flat[key] = v
+	}
+	return nil
+}
+
+// escapeProperty escapes what unescapeProperty would otherwise interpret.
+// Separators, spaces and a leading comment mark only need escaping in keys,
+// a leading space also in values.
+func escapeProperty(s string, isKey bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case isKey && strings.ContainsRune("=: ", r), r == ' ' && i == 0:
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case isKey && (r == '#' || r == '!') && i == 0:
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,211 @@
+package code
+
+import (
+	"code/internal/formatters"
+	"code/internal/models"
+	"sort"
+)
+
+// GenThreeWayDiff compares two configuration files derived from a common
+// base and reports per key whether it changed in ours, in theirs, in both
+// identically, or in both with conflicting values.
+//
+// Parameters:
+//   - base: path to the common ancestor, "-" for stdin
+//   - ours: path to the first derived file, "-" for stdin
+//   - theirs: path to the second derived file, "-" for stdin
+//   - format: output format ("stylish", "plain", or "json")
+//   - opts: optional behaviour settings; WithInputFormats sets the formats
+//     of base, ours and theirs in that order
+func GenThreeWayDiff(base, ours, theirs, format string, opts ...Option) (string, error) {
+	options, err := newOptions(append([]Option{WithFormat(format)}, opts...))
+	if err != nil {
+		return "", err
+	}
+	tree, err := DiffThreeWayFiles(base, ours, theirs, WithOptions(options))
+	if err != nil {
+		return "", err
+	}
+	return formatters.FormatThreeWay(tree, options.Format)
+}
+
+// DiffThreeWayFiles reads and decodes the base, ours and theirs files and
+// returns their three-way diff tree without formatting it.
+func DiffThreeWayFiles(base, ours, theirs string, opts ...Option) ([]ThreeWayNode, error) {
+	options, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	filesData, err := readFiles([]string{base, ours, theirs}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	maps, err := decodeFiles(filesData, options)
+	if err != nil {
+		return nil, err
+	}
+	return buildThreeWayDiff(maps[0], maps[1], maps[2], options), nil
+}
+
+// buildThreeWayDiff computes the diff trees of ours and theirs against base
+// and merges them.
+func buildThreeWayDiff(base, ours, theirs map[string]any, opts Options) []models.ThreeWayNode {
+	return buildThreeWayTree(buildDiffTree(base, ours, nil, opts), buildDiffTree(base, theirs, nil, opts), nil, opts)
+}
+
+// buildThreeWayTree merges two diff trees made against the same base into
+// one three-way tree, sorted by key. Keys missing from a tree are absent
+// from both the base and that side.
+func buildThreeWayTree(ours, theirs []models.DiffNode, path []string, opts Options) []models.ThreeWayNode {
+	oursByKey := nodesByKey(ours)
+	theirsByKey := nodesByKey(theirs)
+
+	keys := make([]string, 0, len(oursByKey)+len(theirsByKey))
+	for k := range oursByKey {
+		keys = append(keys, k)
+	}
+	for k := range theirsByKey {
+		if _, ok := oursByKey[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	nodes := make([]models.ThreeWayNode, 0, len(keys))
+	for _, key := range keys {
+		o, inOurs := oursByKey[key]
+		t, inTheirs := theirsByKey[key]
+		nodes = append(nodes, mergeDiffNodes(key, o, inOurs, t, inTheirs, appendPath(path, key), opts))
+	}
+	return nodes
+}
+
+func nodesByKey(nodes []models.DiffNode) map[string]models.DiffNode {
+	byKey := make(map[string]models.DiffNode, len(nodes))
+	for _, node := range nodes {
+		byKey[node.Key] = node
+	}
+	return byKey
+}
+
+// mergeDiffNodes classifies one key from its diff nodes against the base.
+// Objects present in all three files are merged key by key when either side
+// changed them; everything else, arrays included, is compared as a whole.
+func mergeDiffNodes(key string, ours models.DiffNode, inOurs bool, theirs models.DiffNode, inTheirs bool, path []string, opts Options) models.ThreeWayNode {
+	node := models.ThreeWayNode{Key: key}
+
+	base, oursVal := nodeSides(ours, inOurs)
+	theirsBase, theirsVal := nodeSides(theirs, inTheirs)
+	if base == nil {
+		base = theirsBase
+	}
+	oursChanged := inOurs && nodeChanged(ours)
+	theirsChanged := inTheirs && nodeChanged(theirs)
+
+	switch {
+	case !oursChanged && !theirsChanged:
+		node.Status = models.ThreeWayUnchanged
+	case isMap(base) && isMap(oursVal) && isMap(theirsVal):
+		node.Status = models.ThreeWayNested
+		node.Children = buildThreeWayTree(nestedChildren(ours, path, opts), nestedChildren(theirs, path, opts), path, opts)
+		return node
+	case !theirsChanged:
+		node.Status = models.ThreeWayOurs
+	case !oursChanged:
+		node.Status = models.ThreeWayTheirs
+	case sameValue(oursVal, theirsVal, opts):
+		node.Status = models.ThreeWayBoth
+	default:
+		node.Status = models.ThreeWayConflict
+	}
+
+	node.Base, node.Ours, node.Theirs = base, oursVal, theirsVal
+	return node
+}
+
+// nodeSides returns the value a diff node had in the old and the new file,
+// nil where it was absent. Nested values are rebuilt from the children.
+func nodeSides(node models.DiffNode, present bool) (old, new *any) {
+	if !present {
+		return nil, nil
+	}
+
+	switch node.Type {
+	case models.NodeTypeAdded:
+		return nil, valuePtr(node.NewValue)
+	case models.NodeTypeRemoved:
+		return valuePtr(node.OldValue), nil
+	case models.NodeTypeUnchanged, models.NodeTypeEquivalent:
+		return valuePtr(node.OldValue), valuePtr(node.OldValue)
+	case models.NodeTypeNested:
+		oldMap := make(map[string]any, len(node.Children))
+		newMap := make(map[string]any, len(node.Children))
+		for _, child := range node.Children {
+			o, n := nodeSides(child, true)
+			if o != nil {
+				oldMap[child.Key] = *o
+			}
+			if n != nil {
+				newMap[child.Key] = *n
+			}
+		}
+		return valuePtr(oldMap), valuePtr(newMap)
+	}
+	return valuePtr(node.OldValue), valuePtr(node.NewValue)
+}
+
+// nodeChanged reports whether a diff node holds any difference.
+func nodeChanged(node models.DiffNode) bool {
+	switch node.Type {
+	case models.NodeTypeUnchanged, models.NodeTypeEquivalent:
+		return false
+	case models.NodeTypeNested, models.NodeTypeArray:
+		for _, child := range node.Children {
+			if nodeChanged(child) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// nestedChildren returns the children of an object node, diffing an
+// unchanged object against itself so both sides can be merged key by key.
+func nestedChildren(node models.DiffNode, path []string, opts Options) []models.DiffNode {
+	if node.Type == models.NodeTypeNested {
+		return node.Children
+	}
+	m, _ := node.OldValue.(map[string]any)
+	return buildDiffTree(m, m, path, opts)
+}
+
+func valuePtr(value any) *any {
+	return &value
+}
+
+func isMap(value *any) bool {
+	if value == nil {
+		return false
+	}
+	_, ok := (*value).(map[string]any)
+	return ok
+}
+
+// sameValue compares two possibly absent values. An absent value equals
+// null under the null-absent loose rule.
+func sameValue(a, b *any, opts Options) bool {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return true
+		}
+		present := a
+		if present == nil {
+			present = b
+		}
+		return *present == nil && opts.coerces(CoerceNullAbsent)
+	}
+	return valuesEqual(*a, *b, opts)
+}
@@ -0,0 +1,102 @@
+package code
+
+import (
+	"code/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func parseTOML(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := toml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	normalizeTOMLValue(m)
+	return m, nil
+}
+
+// normalizeTOMLValue replaces the temporal types produced by the TOML decoder
+// with models.DateTime and its int64 integers with int, as the YAML decoder
+// yields, so that TOML and YAML files compare equal. Maps and lists are
+// updated in place; the possibly replaced value is returned.
+func normalizeTOMLValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalizeTOMLValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeTOMLValue(item)
+		}
+	case int64:
+		if int64(int(v)) == v {
+			return int(v)
+		}
+	case time.Time:
+		return models.DateTime{Kind: models.DateTimeOffset, Value: v.Format(time.RFC3339Nano)}
+	case toml.LocalDateTime:
+		return models.DateTime{Kind: models.DateTimeLocal, Value: v.String()}
+	case toml.LocalDate:
+		return models.DateTime{Kind: models.DateLocal, Value: v.String()}
+	case toml.LocalTime:
+		return models.DateTime{Kind: models.TimeLocal, Value: v.String()}
+	}
+	return value
+}
+
+func serializeTOML(doc map[string]any) ([]byte, error) {
+	value, err := denormalizeTOMLValue(deepCopy(doc))
+	if err != nil {
+		return nil, err
+	}
+	return toml.Marshal(value)
+}
+
+// denormalizeTOMLValue turns models.DateTime back into the temporal types
+// the TOML encoder writes as date and time literals, in place.
+func denormalizeTOMLValue(value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			converted, err := denormalizeTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = converted
+		}
+	case []any:
+		for i, item := range v {
+			converted, err := denormalizeTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+	case models.DateTime:
+		return parseDateTime(v)
+	}
+	return value, nil
+}
+
+func parseDateTime(d models.DateTime) (any, error) {
+	switch d.Kind {
+	case models.DateTimeOffset:
+		return time.Parse(time.RFC3339Nano, d.Value)
+	case models.DateTimeLocal:
+		var v toml.LocalDateTime
+		err := v.UnmarshalText([]byte(d.Value))
+		return v, err
+	case models.DateLocal:
+		var v toml.LocalDate
+		err := v.UnmarshalText([]byte(d.Value))
+		return v, err
+	case models.TimeLocal:
+		var v toml.LocalTime
+		err := v.UnmarshalText([]byte(d.Value))
+		return v, err
+	}
+	return nil, fmt.Errorf("unknown date time kind: %s", d.Kind)
+}
@@ -0,0 +1,53 @@
+package code
+
+import (
+	"code/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTypeChanged is returned, wrapped with the paths concerned, by the diff
+// functions when WithFailOnTypeChange is set and a value changed between an
+// object, an array and a scalar.
+var ErrTypeChanged = errors.New("value changed type")
+
+// TypeChanges returns the paths of the type changes in a diff tree, written
+// as in the plain format: "db.host", "servers[2]".
+func TypeChanges(nodes []DiffNode) []string {
+	return collectTypeChanges(nodes, "", false, nil)
+}
+
+func collectTypeChanges(nodes []models.DiffNode, parent string, inArray bool, paths []string) []string {
+	for _, node := range nodes {
+		path := node.Key
+		switch {
+		case inArray:
+			path = parent + "[" + node.Key + "]"
+		case parent != "":
+			path = parent + "." + node.Key
+		}
+
+		switch node.Type {
+		case models.NodeTypeTypeChanged:
+			paths = append(paths, path)
+		case models.NodeTypeNested:
+			paths = collectTypeChanges(node.Children, path, false, paths)
+		case models.NodeTypeArray:
+			paths = collectTypeChanges(node.Children, path, true, paths)
+		}
+	}
+	return paths
+}
+
+// checkTypeChanges fails with ErrTypeChanged when the options forbid type
+// changes and the diff tree has some.
+func checkTypeChanges(nodes []models.DiffNode, opts Options) error {
+	if !opts.FailOnTypeChange {
+		return nil
+	}
+	if paths := TypeChanges(nodes); len(paths) > 0 {
+		return fmt.Errorf("%w: %s", ErrTypeChanged, strings.Join(paths, ", "))
+	}
+	return nil
+}
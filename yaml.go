@@ -0,0 +1,131 @@
+package code
+
+import (
+	"code/internal/models"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// normalizeYAMLValue replaces the time.Time values the YAML decoder yields
+// for timestamps with models.DateTime, as TOML dates and times are, so that
+// TOML and YAML files compare equal. The node the value was decoded from
+// gives the literal, which tells a date from a date and time and a local
+// date and time from one with an offset. Nested mappings with non-string
+// keys get string keys, see yamlStringKeys. Maps and lists are updated in
+// place; the possibly replaced value is returned.
+func normalizeYAMLValue(node *yaml.Node, value any) any {
+	for node != nil && (node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else if len(node.Content) > 0 {
+			node = node.Content[0]
+		} else {
+			node = nil
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		children := make(map[string]*yaml.Node)
+		if node != nil && node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				children[node.Content[i].Value] = node.Content[i+1]
+			}
+		}
+		for k, item := range v {
+			v[k] = normalizeYAMLValue(children[k], item)
+		}
+	case map[any]any:
+		return normalizeYAMLValue(node, yamlStringKeys(node, v))
+	case []any:
+		for i, item := range v {
+			var child *yaml.Node
+			if node != nil && node.Kind == yaml.SequenceNode && i < len(node.Content) {
+				child = node.Content[i]
+			}
+			v[i] = normalizeYAMLValue(child, item)
+		}
+	case time.Time:
+		literal := ""
+		if node != nil {
+			literal = node.Value
+		}
+		return yamlDateTime(literal, v)
+	}
+	return value
+}
+
+// yamlStringKeys turns a map with non-string keys, which the YAML decoder
+// yields for nested mappings with keys such as 1 or true, into a map keyed
+// by the literal keys, as the top-level mapping is. Keys without a literal,
+// e.g. pulled in by a merge key, are formatted with fmt.Sprint.
+func yamlStringKeys(node *yaml.Node, v map[any]any) map[string]any {
+	m := make(map[string]any, len(v))
+	if node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			var key any
+			if err := node.Content[i].Decode(&key); err != nil || (key != nil && !reflect.TypeOf(key).Comparable()) {
+				continue
+			}
+			if item, ok := v[key]; ok {
+				m[node.Content[i].Value] = item
+				delete(v, key)
+			}
+		}
+	}
+	for key, item := range v {
+		m[fmt.Sprint(key)] = item
+	}
+	return m
+}
+
+// yamlDateTime classifies a YAML timestamp by its literal: a date alone,
+// a date and time without a time zone, or one with a zone. Without the
+// literal, e.g. for values pulled in by a merge key, it is taken as a date
+// and time with an offset.
+func yamlDateTime(literal string, t time.Time) models.DateTime {
+	literal = strings.TrimSpace(literal)
+	switch {
+	case literal != "" && len(literal) <= len("2006-01-02"):
+		return models.DateTime{Kind: models.DateLocal, Value: t.Format("2006-01-02")}
+	case literal != "" && !hasZone(literal[len("2006-01-02"):]):
+		return models.DateTime{Kind: models.DateTimeLocal, Value: t.Format("2006-01-02T15:04:05.999999999")}
+	}
+	return models.DateTime{Kind: models.DateTimeOffset, Value: t.Format(time.RFC3339Nano)}
+}
+
+// hasZone reports whether the time part of a timestamp ends with "Z" or a
+// numeric offset.
+func hasZone(timePart string) bool {
+	return strings.HasSuffix(strings.ToUpper(timePart), "Z") || strings.ContainsAny(timePart, "+-")
+}
+
+// denormalizeYAMLValue turns models.DateTime back into YAML timestamps, in
+// place. Local dates and times are written with a space between date and
+// time, the form YAML reads back as a timestamp without a zone. Times of day
+// have no timestamp form and are written as strings.
+func denormalizeYAMLValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = denormalizeYAMLValue(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = denormalizeYAMLValue(item)
+		}
+	case models.DateTime:
+		switch v.Kind {
+		case models.TimeLocal:
+			return v.Value
+		case models.DateTimeLocal:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: strings.Replace(v.Value, "T", " ", 1)}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: v.Value}
+	}
+	return value
+}